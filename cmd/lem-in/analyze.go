@@ -0,0 +1,110 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"lem-in/pkg/lemin"
+)
+
+// runAnalyze compares the turn count of a solution with the proven lower bound:
+//
+//	lem-in analyze [--solver=<name>] [--timeout=<duration>] [--extended] [--verbose] <map> [moves | -]
+//
+// Without a moves file the map is solved with the chosen solver; with one the
+// transcript is verified and its turns are compared instead ("-" reads it from stdin).
+// runAnalyze сравнивает число ходов решения с доказанной нижней границей. Без файла
+// ходов карта решается выбранной стратегией; с ним проверяется и оценивается запись ходов.
+func runAnalyze(args []string) int {
+	fs := newFlagSet("analyze", "analyze [--solver=<name>] [--timeout=<duration>] [--extended] [--verbose] <map> [moves | -]")
+	solverName := fs.String("solver", lemin.DefaultSolver, "path selection strategy: "+strings.Join(lemin.Solvers(), ", "))
+	timeout := fs.Duration("timeout", 0, "time limit for solving, e.g. 2s (0 means no limit)")
+	extended := fs.Bool("extended", false, "accept several start and end rooms")
+	verbose := fs.Bool("verbose", false, "list on stderr the rooms pruned from the search")
+	if status, ok := parseFlags(fs, args); !ok {
+		return status
+	}
+	if fs.NArg() < 1 || fs.NArg() > 2 {
+		return usageError(fs)
+	}
+	if !slices.Contains(lemin.Solvers(), *solverName) {
+		return unknownSolver(*solverName)
+	}
+
+	farm, err := lemin.ParseFile(fs.Arg(0), lemin.ParseOptions{Extended: *extended})
+	if err != nil {
+		return fail(exitParse, err)
+	}
+	bound, err := lemin.LowerBound(farm)
+	if err != nil {
+		return solveError(err)
+	}
+	reportPruned(bound.Pruned, *verbose)
+
+	var achieved, status int
+	var source string
+	if fs.NArg() == 2 {
+		achieved, source, status = analyzeTranscript(fs.Arg(1), farm)
+	} else {
+		achieved, source, status = analyzeSolver(farm, *solverName, *timeout)
+	}
+	if status != exitOK {
+		return status
+	}
+
+	fmt.Printf("Lower bound: %d turns (shortest path %d, min cut %d, %d ants)\n",
+		bound.Turns, bound.Shortest, bound.Cut, bound.Ants)
+	fmt.Printf("Achieved:    %d turns (%s)\n", achieved, source)
+	if gap := achieved - bound.Turns; gap > 0 {
+		fmt.Printf("Gap:         %d turn(s) above the bound\n", gap)
+	} else {
+		fmt.Println("Gap:         0 turns, the solution is optimal")
+	}
+	return exitOK
+}
+
+// analyzeSolver решает карту выбранной стратегией и возвращает число ходов симуляции
+func analyzeSolver(farm *lemin.Farm, name string, timeout time.Duration) (int, string, int) {
+	ctx, cancel := withTimeout(timeout)
+	defer cancel()
+	sol, err := lemin.Solve(ctx, farm, lemin.SolveOptions{Solver: name})
+	if err != nil {
+		return 0, "", solveError(err)
+	}
+
+	turns := lemin.Simulate(farm, sol)
+	if sol.Partial {
+		name += ", time limit reached"
+	}
+	return len(turns), name, exitOK
+}
+
+// analyzeTranscript проверяет запись ходов и возвращает ее число ходов
+func analyzeTranscript(path string, farm *lemin.Farm) (int, string, int) {
+	input := io.Reader(os.Stdin)
+	if path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			return 0, "", fail(exitFailure, "ERROR: cannot read moves:", err)
+		}
+		defer file.Close()
+		input = file
+	}
+
+	moves, err := lemin.ParseMoves(input)
+	if err != nil {
+		return 0, "", fail(exitParse, err)
+	}
+	turns, err := lemin.Verify(farm, moves)
+	var violation *lemin.Violation
+	if errors.As(err, &violation) {
+		fmt.Printf("INVALID: %v\n", violation)
+		return 0, "", exitFailure
+	}
+	return turns, "transcript", exitOK
+}
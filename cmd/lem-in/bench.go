@@ -0,0 +1,256 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"lem-in/pkg/lemin"
+)
+
+// benchMap is one map of the corpus with the name shown in the report.
+// benchMap — одна карта корпуса с именем для отчета.
+type benchMap struct {
+	name string
+	farm *lemin.Farm
+}
+
+// benchResult is the measurement of one solver on one map; bound is zero when
+// the lower bound could not be computed.
+// benchResult — измерение одной стратегии на одной карте; bound равен нулю,
+// если нижнюю границу вычислить не удалось.
+type benchResult struct {
+	name, solver string
+	status       string
+	turns, bound int
+	elapsed      time.Duration
+	allocs       uint64
+}
+
+// runBench compares solver strategies on a corpus of maps:
+//
+//	lem-in bench [--solvers=a,b] [--dir=<maps> | --profile=<name> --seeds=<from-to>] [--format=table|csv]
+//
+// Each solver is timed on every map; the report shows wall time, allocations,
+// turns and the gap to the lower bound, followed by totals per solver.
+// runBench сравнивает стратегии на корпусе карт: время, выделения памяти, число
+// ходов и разрыв до нижней границы для каждой карты, затем итоги по стратегиям.
+func runBench(args []string) int {
+	fs := newFlagSet("bench", "bench [--solvers=a,b] [--dir=<maps> | --profile=<name> --seeds=<from-to>] [--format=table|csv] [--timeout=<duration>]")
+	solvers := fs.String("solvers", strings.Join(lemin.Solvers(), ","), "comma-separated strategies to compare")
+	dir := fs.String("dir", "", "directory of map files (*.txt)")
+	profile := fs.String("profile", "", "generate the corpus with this profile: "+strings.Join(lemin.Profiles(), ", "))
+	seeds := fs.String("seeds", "1-10", "seed range for generated maps, e.g. 1-20")
+	ants := fs.Int("ants", 0, "number of ants in generated maps (0 means the profile default)")
+	size := fs.Int("size", 0, "size of generated maps (0 means the profile default)")
+	format := fs.String("format", "table", "report format: table or csv")
+	timeout := fs.Duration("timeout", 10*time.Second, "time limit per solver and map (0 means no limit)")
+	extended := fs.Bool("extended", false, "accept several start and end rooms")
+	if status, ok := parseFlags(fs, args); !ok {
+		return status
+	}
+	if fs.NArg() != 0 || (*dir == "") == (*profile == "") || (*format != "table" && *format != "csv") {
+		return usageError(fs)
+	}
+
+	var strategies []string
+	for _, name := range strings.Split(*solvers, ",") {
+		if !slices.Contains(lemin.Solvers(), name) {
+			return unknownSolver(name)
+		}
+		strategies = append(strategies, name)
+	}
+
+	var corpus []benchMap
+	var err error
+	if *dir != "" {
+		corpus, err = loadCorpus(*dir, *extended)
+	} else {
+		corpus, err = generateCorpus(*profile, *seeds, *ants, *size)
+	}
+	if err != nil {
+		return fail(exitFailure, "ERROR:", err)
+	}
+
+	var results []benchResult
+	for _, m := range corpus {
+		bound, err := lemin.LowerBound(m.farm)
+		for _, name := range strategies {
+			// Without a lower bound there is nothing to compare with: the error becomes the status
+			// Без нижней границы не с чем сравнивать: ошибка становится состоянием карты
+			if err != nil {
+				results = append(results, benchResult{name: m.name, solver: name, status: "bound: " + err.Error()})
+				continue
+			}
+			r := measure(m.farm, name, *timeout)
+			r.name, r.bound = m.name, bound.Turns
+			results = append(results, r)
+		}
+	}
+
+	if *format == "csv" {
+		writeBenchCSV(results)
+	} else {
+		writeBenchTable(results, strategies)
+	}
+	return exitOK
+}
+
+// measure solves the map once and counts the heap allocations made by the solver,
+// including building and pruning the graph.
+// measure решает карту один раз и считает выделения памяти стратегией,
+// включая построение и прореживание графа.
+func measure(farm *lemin.Farm, name string, timeout time.Duration) benchResult {
+	ctx, cancel := withTimeout(timeout)
+	defer cancel()
+
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	began := time.Now()
+	sol, err := lemin.Solve(ctx, farm, lemin.SolveOptions{Solver: name})
+	elapsed := time.Since(began)
+	runtime.ReadMemStats(&after)
+
+	r := benchResult{solver: name, status: "ok", elapsed: elapsed, allocs: after.Mallocs - before.Mallocs}
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		r.status = "timeout"
+		return r
+	case err != nil:
+		r.status = "no path"
+		return r
+	case sol.Partial:
+		r.status = "partial"
+	}
+	r.turns = len(lemin.Simulate(farm, sol))
+	return r
+}
+
+// loadCorpus parses every *.txt file of a directory in name order.
+// loadCorpus разбирает каждый файл *.txt каталога в порядке имен.
+func loadCorpus(dir string, extended bool) ([]benchMap, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.txt"))
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no *.txt maps in %s", dir)
+	}
+	sort.Strings(files)
+
+	var corpus []benchMap
+	for _, file := range files {
+		farm, err := lemin.ParseFile(file, lemin.ParseOptions{Extended: extended})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "WARNING: skipping %s: %v\n", file, err)
+			continue
+		}
+		corpus = append(corpus, benchMap{name: filepath.Base(file), farm: farm})
+	}
+	return corpus, nil
+}
+
+// generateCorpus builds one map per seed of the range "from-to" (or a single seed).
+// generateCorpus строит по карте на каждое зерно диапазона "from-to" (или одно зерно).
+func generateCorpus(profile, seeds string, ants, size int) ([]benchMap, error) {
+	fromText, toText, found := strings.Cut(seeds, "-")
+	if !found {
+		toText = fromText
+	}
+	from, err1 := strconv.ParseUint(fromText, 10, 64)
+	to, err2 := strconv.ParseUint(toText, 10, 64)
+	if err1 != nil || err2 != nil || from > to {
+		return nil, fmt.Errorf("invalid seed range %q", seeds)
+	}
+
+	var corpus []benchMap
+	for seed := from; seed <= to; seed++ {
+		lines, err := lemin.Generate(lemin.GenerateOptions{Profile: profile, Seed: seed, Ants: ants, Size: size})
+		if err != nil {
+			return nil, err
+		}
+		farm, err := lemin.Parse(strings.NewReader(strings.Join(lines, "\n")), lemin.ParseOptions{})
+		if err != nil {
+			return nil, err
+		}
+		corpus = append(corpus, benchMap{name: fmt.Sprintf("%s-%d", profile, seed), farm: farm})
+	}
+	return corpus, nil
+}
+
+// writeBenchTable prints one row per solver and map, then the totals of each solver.
+// writeBenchTable печатает строку на каждую пару стратегии и карты, затем итоги стратегий.
+func writeBenchTable(results []benchResult, strategies []string) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "map\tsolver\tstatus\tturns\tbound\tgap\ttime\tallocs\t")
+	for _, r := range results {
+		turns, bound, gap := r.columns("-")
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%v\t%d\t\n",
+			r.name, r.solver, r.status, turns, bound, gap, r.elapsed.Round(time.Microsecond), r.allocs)
+	}
+	w.Flush()
+
+	fmt.Println()
+	w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "solver\tsolved\tturns\tgap\ttime\tallocs\t")
+	for _, name := range strategies {
+		var solved, turns, gap int
+		var elapsed time.Duration
+		var allocs uint64
+		for _, r := range results {
+			if r.solver != name {
+				continue
+			}
+			elapsed += r.elapsed
+			allocs += r.allocs
+			if r.turns > 0 {
+				solved++
+				turns += r.turns
+				gap += r.turns - r.bound
+			}
+		}
+		fmt.Fprintf(w, "%s\t%d/%d\t%d\t%d\t%v\t%d\t\n",
+			name, solved, len(results)/len(strategies), turns, gap, elapsed.Round(time.Microsecond), allocs)
+	}
+	w.Flush()
+}
+
+// writeBenchCSV prints every measurement as a CSV row, with time in microseconds.
+// writeBenchCSV печатает каждое измерение строкой CSV, время — в микросекундах.
+func writeBenchCSV(results []benchResult) {
+	w := csv.NewWriter(os.Stdout)
+	w.Write([]string{"map", "solver", "status", "turns", "bound", "gap", "time_us", "allocs"})
+	for _, r := range results {
+		turns, bound, gap := r.columns("")
+		w.Write([]string{r.name, r.solver, r.status, turns, bound, gap,
+			strconv.FormatInt(r.elapsed.Microseconds(), 10), strconv.FormatUint(r.allocs, 10)})
+	}
+	w.Flush()
+}
+
+// columns formats the turns, the bound and the gap, with none in place of a missing value.
+// columns форматирует ходы, границу и разрыв, подставляя none вместо отсутствующего значения.
+func (r benchResult) columns(none string) (turns, bound, gap string) {
+	turns, bound, gap = none, none, none
+	if r.turns > 0 {
+		turns = strconv.Itoa(r.turns)
+	}
+	if r.bound > 0 {
+		bound = strconv.Itoa(r.bound)
+	}
+	if r.turns > 0 && r.bound > 0 {
+		gap = strconv.Itoa(r.turns - r.bound)
+	}
+	return turns, bound, gap
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"lem-in/pkg/lemin"
+)
+
+// runExport writes a map in another format without solving it:
+//
+//	lem-in export [--format=dot|json] [--lenient] [--extended] <map | ->
+//
+// DOT output can be rendered with Graphviz, e.g. "lem-in export map.txt | neato -n -Tsvg".
+// runExport записывает карту в другом формате, не решая ее. Вывод DOT можно
+// отрисовать с помощью Graphviz.
+func runExport(args []string) int {
+	fs := newFlagSet("export", "export [--format=dot|json] [--lenient] [--extended] <map | ->")
+	format := fs.String("format", "dot", "output format: dot or json")
+	lenient := fs.Bool("lenient", false, "warn about bad links instead of rejecting the map")
+	extended := fs.Bool("extended", false, "accept several start and end rooms and per-start ant counts")
+	if status, ok := parseFlags(fs, args); !ok {
+		return status
+	}
+	if fs.NArg() != 1 {
+		return usageError(fs)
+	}
+	if *format != "dot" && *format != "json" {
+		return fail(exitUsage, fmt.Sprintf("ERROR: unknown format %q, available: dot, json", *format))
+	}
+
+	farm, err := parseInput(lemin.ParseOptions{Lenient: *lenient, Extended: *extended, Warn: printWarning}, fs.Arg(0))
+	if err != nil {
+		return fail(exitParse, err)
+	}
+
+	write := lemin.WriteDOT
+	if *format == "json" {
+		write = lemin.WriteFarmJSON
+	}
+	if err := write(os.Stdout, farm); err != nil {
+		return fail(exitFailure, "ERROR:", err)
+	}
+	return exitOK
+}
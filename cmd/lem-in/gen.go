@@ -0,0 +1,56 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"lem-in/pkg/lemin"
+)
+
+// runGen writes a random map of the chosen shape to stdout:
+//
+//	lem-in gen [--profile=<name>] [--seed=<n>] [--ants=<n>] [--size=<n>] [--with-expected-turns] [--timeout=<duration>]
+//
+// The same options always produce the same map. With --with-expected-turns the
+// proven optimal number of turns is recorded as a "#" comment on the first line.
+// runGen выводит в stdout случайную карту выбранной формы. Одни и те же параметры
+// всегда дают одну и ту же карту. С --with-expected-turns доказанное оптимальное
+// число ходов записывается комментарием "#" в первой строке.
+func runGen(args []string) int {
+	fs := newFlagSet("gen", "gen [--profile=<name>] [--seed=<n>] [--ants=<n>] [--size=<n>] [--with-expected-turns] [--timeout=<duration>]")
+	profile := fs.String("profile", "corridor", "map shape: "+strings.Join(lemin.Profiles(), ", "))
+	seed := fs.Uint64("seed", 1, "random seed")
+	ants := fs.Int("ants", 0, "number of ants (0 means the profile default)")
+	size := fs.Int("size", 0, "corridor length, number of routes, grid side or number of rooms (0 means the profile default)")
+	expected := fs.Bool("with-expected-turns", false, "record the optimal number of turns as a comment")
+	timeout := fs.Duration("timeout", 0, "time limit for proving the optimal number of turns (0 means no limit)")
+	if status, ok := parseFlags(fs, args); !ok {
+		return status
+	}
+	if fs.NArg() != 0 {
+		return usageError(fs)
+	}
+
+	lines, err := lemin.Generate(lemin.GenerateOptions{Profile: *profile, Seed: *seed, Ants: *ants, Size: *size})
+	if err != nil {
+		return fail(exitUsage, "ERROR:", err)
+	}
+
+	if *expected {
+		ctx, cancel := withTimeout(*timeout)
+		defer cancel()
+		turns, err := lemin.ExpectedTurns(ctx, lines)
+		if errors.Is(err, lemin.ErrUnproven) {
+			return fail(exitTimeout, "ERROR:", err)
+		}
+		if err != nil {
+			return fail(exitFailure, "ERROR:", err)
+		}
+		fmt.Printf("#expected turns: %d\n", turns)
+	}
+	for _, line := range lines {
+		fmt.Println(line)
+	}
+	return exitOK
+}
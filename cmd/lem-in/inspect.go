@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"lem-in/pkg/lemin"
+)
+
+// runInspect reports the connectivity of a map, so map designers see why it performs as it does:
+//
+//	lem-in inspect [--extended] <map>
+//
+// runInspect сообщает о связности карты, чтобы авторы карт видели, почему она ведет себя именно так.
+func runInspect(args []string) int {
+	fs := newFlagSet("inspect", "inspect [--extended] <map>")
+	extended := fs.Bool("extended", false, "accept several start and end rooms")
+	if status, ok := parseFlags(fs, args); !ok {
+		return status
+	}
+	if fs.NArg() != 1 {
+		return usageError(fs)
+	}
+
+	farm, err := lemin.ParseFile(fs.Arg(0), lemin.ParseOptions{Extended: *extended})
+	if err != nil {
+		return fail(exitParse, err)
+	}
+	c := lemin.Inspect(farm)
+
+	fmt.Printf("Throughput:          %d ants per turn\n", c.Throughput)
+	fmt.Printf("Min cut rooms:       %s\n", listOrNone(c.MinCutRooms))
+	fmt.Printf("Min cut tunnels:     %s\n", listOrNone(c.MinCutTunnels))
+	fmt.Printf("Articulation points: %s\n", listOrNone(c.ArticulationPoints))
+	fmt.Printf("Bridges:             %s\n", listOrNone(c.Bridges))
+	fmt.Printf("Unreachable rooms:   %s\n", listOrNone(c.Unreachable))
+	fmt.Printf("Dead ends:           %s\n", listOrNone(c.DeadEnds))
+	return exitOK
+}
+
+// listOrNone joins names with commas, or says there are none.
+// listOrNone соединяет имена через запятую или сообщает, что их нет.
+func listOrNone(names []string) string {
+	if len(names) == 0 {
+		return "none"
+	}
+	return strings.Join(names, ", ")
+}
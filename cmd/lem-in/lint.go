@@ -0,0 +1,43 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"lem-in/pkg/lemin"
+)
+
+// runLint reports every problem in a map instead of stopping at the first one:
+//
+//	lem-in lint [--extended] <file | ->
+//
+// It exits with the parse error status when any problem is found.
+// runLint сообщает обо всех проблемах карты, а не только о первой,
+// и завершается с кодом ошибки разбора, если найдена хотя бы одна.
+func runLint(args []string) int {
+	fs := newFlagSet("lint", "lint [--extended] <file | ->")
+	extended := fs.Bool("extended", false, "accept several start and end rooms")
+	if status, ok := parseFlags(fs, args); !ok {
+		return status
+	}
+	if fs.NArg() != 1 {
+		return usageError(fs)
+	}
+
+	opts := lemin.ParseOptions{Extended: *extended}
+	var errs []*lemin.ParseError
+	if fs.Arg(0) == "-" {
+		errs = lemin.Lint(os.Stdin, opts)
+	} else {
+		errs = lemin.LintFile(fs.Arg(0), opts)
+	}
+	for _, err := range errs {
+		fmt.Println(err)
+	}
+	if len(errs) > 0 {
+		fmt.Printf("%d problem(s) found\n", len(errs))
+		return exitParse
+	}
+	fmt.Println("OK")
+	return exitOK
+}
@@ -1,42 +1,211 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"os"
+	"strings"
+	"time"
 
-	"lem-in/internal/formatter"
-	"lem-in/internal/graph"
-	"lem-in/internal/parser"
-	"lem-in/internal/simulation"
-	"lem-in/internal/solver"
+	"lem-in/pkg/lemin"
 )
 
+// Exit statuses shared by every subcommand.
+// Коды выхода, общие для всех подкоманд.
+const (
+	exitOK      = 0
+	exitFailure = 1 // the work was done but its result is negative: invalid transcript, failed maps
+	exitUsage   = 2
+	exitParse   = 3
+	exitNoPath  = 4
+	exitTimeout = 5
+)
+
+// command is a subcommand with the one-line description shown by --help.
+// command — подкоманда с однострочным описанием для --help.
+type command struct {
+	name    string
+	summary string
+	run     func(args []string) int
+}
+
+// commands lists the subcommands in the order --help shows them; any other
+// first argument is a map file solved as by "solve".
+// commands перечисляет подкоманды в порядке вывода --help; любой другой
+// первый аргумент — файл карты, который решается как в "solve".
+var commands = []command{
+	{"solve", "solve a map, or every map of a directory with --dir", runSolve},
+	{"validate", "check a move transcript against a map", runValidate},
+	{"lint", "list every problem of a map", runLint},
+	{"inspect", "report the min cut, articulation points, bridges and dead ends of a map", runInspect},
+	{"analyze", "compare the turns of a solution with the proven lower bound", runAnalyze},
+	{"gen", "generate a random map from a seed", runGen},
+	{"bench", "compare solvers on a corpus of maps", runBench},
+	{"export", "write a map as Graphviz DOT or JSON", runExport},
+}
+
 func main() {
-	if len(os.Args) != 2 {
-		fmt.Println("Usage: go run . <filename>")
-		return
+	os.Exit(run(os.Args[1:]))
+}
+
+// run dispatches the arguments to a subcommand and returns the exit status.
+// run передает аргументы подкоманде и возвращает код выхода.
+func run(args []string) int {
+	if len(args) > 0 {
+		switch args[0] {
+		case "help", "-h", "-help", "--help":
+			printHelp()
+			return exitOK
+		}
+		for _, c := range commands {
+			if c.name == args[0] {
+				return c.run(args[1:])
+			}
+		}
+	}
+	// Without a subcommand the arguments are those of "solve", so that
+	// "lem-in <file>" keeps its output
+	// Без подкоманды аргументы относятся к "solve", чтобы
+	// "lem-in <file>" сохранял прежний вывод
+	return runSolve(args)
+}
+
+// printHelp lists the subcommands on stdout.
+// printHelp перечисляет подкоманды в stdout.
+func printHelp() {
+	fmt.Println("Usage: lem-in [solve flags] <map | ->")
+	fmt.Println("       lem-in <command> [flags] [arguments]")
+	fmt.Println()
+	fmt.Println("Commands:")
+	for _, c := range commands {
+		fmt.Printf("  %-9s %s\n", c.name, c.summary)
+	}
+	fmt.Println()
+	fmt.Println(`Run "lem-in <command> --help" for the flags of a command.`)
+	fmt.Println("Flags may come before or after the map and other arguments.")
+	fmt.Println()
+	fmt.Println("Exit status: 0 success, 1 negative result, 2 usage error, 3 parse error, 4 no path, 5 timeout.")
+}
+
+// newFlagSet creates the flag set of a subcommand; its usage line and flags
+// are printed on stderr for --help and for usage errors.
+// newFlagSet создает набор флагов подкоманды; строка использования и флаги
+// выводятся в stderr для --help и при ошибках использования.
+func newFlagSet(name, usage string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: lem-in %s\n", usage)
+		fmt.Fprintln(os.Stderr, "\nFlags:")
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parseFlags parses the arguments of a subcommand. Flags may come before or after
+// the positional arguments; everything after "--" is positional. When it returns
+// false the subcommand must stop with the returned status: 0 after --help, 2 on bad flags.
+// parseFlags разбирает аргументы подкоманды. Флаги могут стоять до или после
+// позиционных аргументов; все после "--" считается позиционным. Если возвращено
+// false, подкоманда завершается с возвращенным кодом: 0 после --help, 2 при неверных флагах.
+func parseFlags(fs *flag.FlagSet, args []string) (int, bool) {
+	var positional []string
+	for {
+		err := fs.Parse(args)
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK, false
+		}
+		if err != nil {
+			return exitUsage, false
+		}
+		// flag.Parse stops at the first positional argument, so it is set aside
+		// and the rest is parsed again
+		// flag.Parse останавливается на первом позиционном аргументе, поэтому
+		// откладываем его и разбираем остаток заново
+		rest := fs.Args()
+		if len(rest) == 0 {
+			break
+		}
+		if len(rest) < len(args) && args[len(args)-len(rest)-1] == "--" {
+			positional = append(positional, rest...)
+			break
+		}
+		positional = append(positional, rest[0])
+		args = rest[1:]
 	}
+	// Parsing a lone "--" keeps the flags and sets the positional arguments
+	// Разбор одного "--" оставляет флаги как есть и задает позиционные аргументы
+	fs.Parse(append([]string{"--"}, positional...))
+	return exitOK, true
+}
+
+// usageError prints the usage of a subcommand and returns the usage status.
+// usageError выводит справку подкоманды и возвращает код ошибки использования.
+func usageError(fs *flag.FlagSet) int {
+	fs.Usage()
+	return exitUsage
+}
+
+// fail prints an error on stderr and returns the given status.
+// fail выводит ошибку в stderr и возвращает данный код.
+func fail(status int, a ...any) int {
+	fmt.Fprintln(os.Stderr, a...)
+	return status
+}
+
+// unknownSolver reports a strategy name that is not registered.
+// unknownSolver сообщает о незарегистрированном имени стратегии.
+func unknownSolver(name string) int {
+	return fail(exitUsage, fmt.Sprintf("ERROR: unknown solver %q, available: %s", name, strings.Join(lemin.Solvers(), ", ")))
+}
 
-	// 1. Parsing / Парсинг
-	farm, err := parser.Parse(os.Args[1])
-	if err != nil {
-		fmt.Println(err)
-		return
+// solveError reports why a solver returned no solution.
+// solveError сообщает, почему стратегия не вернула решения.
+func solveError(err error) int {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fail(exitTimeout, "ERROR: time limit exceeded before any path was found")
 	}
+	return fail(exitNoPath, "ERROR: invalid data format, no paths found")
+}
 
-	// 2. Graph Building / Построение графа
-	g := graph.Build(farm)
+// withTimeout returns a context bounded by the timeout unless it is zero.
+// withTimeout возвращает контекст, ограниченный временем timeout, если оно не равно нулю.
+func withTimeout(timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout > 0 {
+		return context.WithTimeout(context.Background(), timeout)
+	}
+	return context.WithCancel(context.Background())
+}
 
-	// 3. Solving / Поиск путей и распределение
-	paths, distribution, err := solver.Solve(g, farm.Ants)
-	if err != nil {
-		fmt.Println("ERROR: invalid data format, no paths found")
-		return
+// reportPruned lists on stderr the rooms left out of the search when verbose is
+// set; otherwise it stays silent, so plain runs print exactly what they always did.
+// reportPruned перечисляет в stderr комнаты, исключенные из поиска, если задан
+// verbose; иначе молчит, и обычный запуск выводит ровно то же, что и всегда.
+func reportPruned(rooms []string, verbose bool) {
+	if verbose && len(rooms) > 0 {
+		fmt.Fprintf(os.Stderr, "NOTE: pruned %d rooms that lie on no path: %s\n", len(rooms), strings.Join(rooms, ", "))
 	}
+}
 
-	// 4. Simulation / Симуляция движений
-	moves := simulation.Run(paths, distribution)
+// printWarning reports on stderr a problem skipped in lenient mode.
+// printWarning сообщает в stderr о проблеме, пропущенной в мягком режиме.
+func printWarning(w *lemin.ParseError) {
+	fmt.Fprintln(os.Stderr, "WARNING:", w.Message())
+}
+
+// parseInput reads the map from a file, or from stdin when path is "-".
+// parseInput читает карту из файла или из stdin, если path равен "-".
+func parseInput(opts lemin.ParseOptions, path string) (*lemin.Farm, error) {
+	if path == "-" {
+		return lemin.Parse(os.Stdin, opts)
+	}
+	return lemin.ParseFile(path, opts)
+}
 
-	// 5. Output / Форматированный вывод
-	formatter.Print(farm.RawLines, moves)
+// stdinIsPiped reports whether stdin is a pipe or a file rather than a terminal.
+// stdinIsPiped сообщает, подключен ли stdin к каналу или файлу, а не к терминалу.
+func stdinIsPiped() bool {
+	info, err := os.Stdin.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice == 0
 }
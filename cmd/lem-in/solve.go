@@ -0,0 +1,266 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"lem-in/pkg/lemin"
+)
+
+// Statuses of a map in the batch summary.
+// Состояния карты в итоговом отчете пакетного режима.
+const (
+	statusOK         = "OK"
+	statusParseError = "parse error"
+	statusNoPath     = "no path"
+	statusTimeout    = "timeout"
+	statusPartial    = "timeout (partial)"
+	statusWriteError = "write error"
+)
+
+// batchResult is the outcome of one map of the batch.
+// batchResult — итог обработки одной карты пакета.
+type batchResult struct {
+	name    string
+	status  string
+	turns   int
+	elapsed time.Duration
+	err     error
+}
+
+// runSolve solves one map, or every map of a directory concurrently:
+//
+//	lem-in [solve] [flags] <map | ->
+//	lem-in solve [flags] --dir=<maps> --out=<results> [--jobs=<n>]
+//
+// A single map is printed followed by the moves; without a file argument a piped
+// map is read from stdin. In batch mode every *.txt map gets an output file of
+// the same name in the output directory (.out for text, .json for JSON), and a
+// summary of all maps is printed at the end; the exit status is 1 when any map failed.
+// runSolve решает одну карту или параллельно все карты каталога. Одна карта
+// выводится вместе с ходами; без аргумента-файла карта из конвейера читается из stdin.
+// В пакетном режиме каждая карта *.txt получает файл вывода с тем же именем
+// в каталоге результатов, в конце печатается итоговый отчет; код выхода равен 1,
+// если хотя бы одна карта не решена.
+func runSolve(args []string) int {
+	fs := newFlagSet("solve", "[solve] [flags] <map | ->\n       lem-in solve [flags] --dir=<maps> --out=<results> [--jobs=<n>]")
+	solverName := fs.String("solver", lemin.DefaultSolver, "path selection strategy: "+strings.Join(lemin.Solvers(), ", "))
+	format := fs.String("format", "text", "output format: text, json or ndjson (text or json with --dir)")
+	lenient := fs.Bool("lenient", false, "warn about bad links instead of rejecting the map")
+	extended := fs.Bool("extended", false, "accept several start and end rooms and per-start ant counts")
+	timeout := fs.Duration("timeout", 0, "time limit for solving a map, e.g. 2s (0 means no limit)")
+	dir := fs.String("dir", "", "solve every map file (*.txt) of this directory")
+	out := fs.String("out", "", "directory for the output files of --dir")
+	jobs := fs.Int("jobs", runtime.NumCPU(), "number of maps solved at once with --dir")
+	noPrune := fs.Bool("no-prune", false, "search every room instead of removing the rooms that lie on no path")
+	verbose := fs.Bool("verbose", false, "list on stderr the rooms pruned from the search")
+	if status, ok := parseFlags(fs, args); !ok {
+		return status
+	}
+
+	if *format != "text" && *format != "json" && (*format != "ndjson" || *dir != "") {
+		return fail(exitUsage, fmt.Sprintf("ERROR: unknown format %q, available: text, json, ndjson", *format))
+	}
+	if !slices.Contains(lemin.Solvers(), *solverName) {
+		return unknownSolver(*solverName)
+	}
+	solve := lemin.SolveOptions{Solver: *solverName, NoPrune: *noPrune}
+
+	if *dir != "" {
+		if fs.NArg() != 0 || *out == "" || *jobs < 1 {
+			return usageError(fs)
+		}
+		return solveDir(lemin.ParseOptions{Lenient: *lenient, Extended: *extended}, solve, *dir, *out, *format, *jobs, *timeout)
+	}
+
+	// With no file argument a piped map is read from stdin, as with "-"
+	// Без аргумента-файла карта из конвейера читается из stdin, как с "-"
+	path := fs.Arg(0)
+	if fs.NArg() == 0 && stdinIsPiped() {
+		path = "-"
+	}
+	if fs.NArg() > 1 || path == "" {
+		return usageError(fs)
+	}
+	return solveMap(lemin.ParseOptions{Lenient: *lenient, Extended: *extended, Warn: printWarning}, solve, path, *format, *timeout, *verbose)
+}
+
+// solveMap solves a single map and prints it with the moves in the chosen format.
+// solveMap решает одну карту и выводит ее с ходами в выбранном формате.
+func solveMap(parse lemin.ParseOptions, solve lemin.SolveOptions, path, format string, timeout time.Duration, verbose bool) int {
+	// 1. Parsing / Парсинг
+	farm, err := parseInput(parse, path)
+	if err != nil {
+		return fail(exitParse, err)
+	}
+
+	// 2. Solving / Поиск путей и распределение
+	ctx, cancel := withTimeout(timeout)
+	defer cancel()
+	sol, err := lemin.Solve(ctx, farm, solve)
+	if err != nil {
+		return solveError(err)
+	}
+	reportPruned(sol.Pruned, verbose)
+	if sol.Partial {
+		// stderr keeps the stdout transcript valid for the visualizer
+		// stderr сохраняет вывод в stdout пригодным для визуализатора
+		fmt.Fprintln(os.Stderr, "WARNING: time limit reached, the solution may not be optimal")
+	}
+
+	// NDJSON streams turns while they are simulated instead of collecting them first
+	// NDJSON передает ходы по мере симуляции, не собирая их заранее
+	if format == "ndjson" {
+		if err := lemin.WriteNDJSON(os.Stdout, lemin.Turns(farm, sol)); err != nil {
+			return fail(exitFailure, "ERROR:", err)
+		}
+		return exitOK
+	}
+
+	// 3. Simulation / Симуляция движений
+	turns := lemin.Simulate(farm, sol)
+
+	// 4. Output / Форматированный вывод
+	if format == "json" {
+		if err := lemin.WriteJSON(os.Stdout, farm, sol, turns); err != nil {
+			return fail(exitFailure, "ERROR:", err)
+		}
+		return exitOK
+	}
+	lemin.WriteText(os.Stdout, farm, turns)
+	return exitOK
+}
+
+// solveDir solves every map of a directory with a pool of workers and prints the summary.
+// solveDir решает все карты каталога пулом обработчиков и печатает итоговый отчет.
+func solveDir(parse lemin.ParseOptions, solve lemin.SolveOptions, dir, out, format string, jobs int, timeout time.Duration) int {
+	files, err := filepath.Glob(filepath.Join(dir, "*.txt"))
+	if err == nil && len(files) == 0 {
+		err = fmt.Errorf("no *.txt maps in %s", dir)
+	}
+	if err == nil {
+		err = os.MkdirAll(out, 0o755)
+	}
+	if err != nil {
+		return fail(exitFailure, "ERROR:", err)
+	}
+	sort.Strings(files)
+
+	// Workers take map indexes from the queue and write results to their own slots
+	// Обработчики берут индексы карт из очереди и пишут итоги в свои ячейки
+	began := time.Now()
+	results := make([]batchResult, len(files))
+	queue := make(chan int)
+	var wg sync.WaitGroup
+	for range min(jobs, len(files)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range queue {
+				results[i] = solveFile(parse, solve, files[i], out, format, timeout)
+			}
+		}()
+	}
+	for i := range files {
+		queue <- i
+	}
+	close(queue)
+	wg.Wait()
+
+	if !writeSummary(results, time.Since(began)) {
+		return exitFailure
+	}
+	return exitOK
+}
+
+// solveFile solves one map and writes its output file.
+// solveFile решает одну карту и записывает ее файл вывода.
+func solveFile(parse lemin.ParseOptions, solve lemin.SolveOptions, path, outDir, format string, timeout time.Duration) (r batchResult) {
+	r.name = filepath.Base(path)
+	began := time.Now()
+	defer func() { r.elapsed = time.Since(began) }()
+
+	farm, err := lemin.ParseFile(path, parse)
+	if err != nil {
+		r.status, r.err = statusParseError, err
+		return r
+	}
+
+	ctx, cancel := withTimeout(timeout)
+	defer cancel()
+	sol, err := lemin.Solve(ctx, farm, solve)
+	if errors.Is(err, context.DeadlineExceeded) {
+		r.status = statusTimeout
+		return r
+	}
+	if err != nil {
+		r.status = statusNoPath
+		return r
+	}
+
+	// A partial solution is still written out, but the map counts as failed
+	// Частичное решение все равно записывается, но карта считается нерешенной
+	turns := lemin.Simulate(farm, sol)
+	r.status, r.turns = statusOK, len(turns)
+	if sol.Partial {
+		r.status = statusPartial
+	}
+
+	ext := ".out"
+	if format == "json" {
+		ext = ".json"
+	}
+	name := strings.TrimSuffix(r.name, filepath.Ext(r.name)) + ext
+	file, err := os.Create(filepath.Join(outDir, name))
+	if err == nil {
+		if format == "json" {
+			err = lemin.WriteJSON(file, farm, sol, turns)
+		} else {
+			err = lemin.WriteText(file, farm, turns)
+		}
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if err != nil {
+		r.status, r.err = statusWriteError, err
+	}
+	return r
+}
+
+// writeSummary prints one line per map and the number of maps in every status;
+// it reports whether all maps were solved.
+// writeSummary печатает строку на каждую карту и число карт в каждом состоянии;
+// сообщает, решены ли все карты.
+func writeSummary(results []batchResult, wall time.Duration) bool {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "map\tstatus\tturns\ttime\tdetails")
+	counts := make(map[string]int)
+	for _, r := range results {
+		turns, details := "-", ""
+		if r.status == statusOK || r.status == statusPartial || r.status == statusWriteError {
+			turns = fmt.Sprint(r.turns)
+		}
+		if r.err != nil {
+			details = r.err.Error()
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%v\t%s\n", r.name, r.status, turns, r.elapsed.Round(time.Microsecond), details)
+		counts[r.status]++
+	}
+	w.Flush()
+
+	fmt.Printf("\n%d maps: %d OK, %d parse error, %d no path, %d timeout, %d partial, %d write error (%v)\n",
+		len(results), counts[statusOK], counts[statusParseError], counts[statusNoPath],
+		counts[statusTimeout], counts[statusPartial], counts[statusWriteError], wall.Round(time.Millisecond))
+	return counts[statusOK] == len(results)
+}
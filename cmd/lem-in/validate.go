@@ -0,0 +1,59 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"lem-in/pkg/lemin"
+)
+
+// runValidate checks a move transcript against a map:
+//
+//	lem-in validate [--extended] <map> [moves]
+//
+// Without a moves file the transcript is read from stdin, so solver output can be piped in.
+// It exits with status 1 when the transcript breaks a rule.
+// runValidate проверяет запись ходов по карте. Без файла ходов запись читается из stdin.
+// Завершается с кодом 1, если запись нарушает правило.
+func runValidate(args []string) int {
+	fs := newFlagSet("validate", "validate [--extended] <map> [moves]")
+	extended := fs.Bool("extended", false, "accept several start and end rooms")
+	if status, ok := parseFlags(fs, args); !ok {
+		return status
+	}
+	if fs.NArg() < 1 || fs.NArg() > 2 {
+		return usageError(fs)
+	}
+
+	farm, err := lemin.ParseFile(fs.Arg(0), lemin.ParseOptions{Extended: *extended})
+	if err != nil {
+		return fail(exitParse, err)
+	}
+
+	input := io.Reader(os.Stdin)
+	if fs.NArg() == 2 {
+		file, err := os.Open(fs.Arg(1))
+		if err != nil {
+			return fail(exitFailure, "ERROR: cannot read moves:", err)
+		}
+		defer file.Close()
+		input = file
+	}
+
+	moves, err := lemin.ParseMoves(input)
+	if err != nil {
+		return fail(exitParse, err)
+	}
+
+	turns, err := lemin.Verify(farm, moves)
+	var violation *lemin.Violation
+	if errors.As(err, &violation) {
+		fmt.Printf("INVALID: %v\n", violation)
+		fmt.Printf("Turns: %d\n", turns)
+		return exitFailure
+	}
+	fmt.Printf("OK: %d turns\n", turns)
+	return exitOK
+}
@@ -7,16 +7,26 @@ import (
 	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
+
+	"lem-in/internal/formatter"
+	"lem-in/internal/models"
+	"lem-in/internal/parser"
 )
 
 type Point struct {
 	X, Y int
 }
 
+// link is a tunnel to draw; one-way tunnels get an arrowhead.
+type link struct {
+	from, to string
+	oneWay   bool
+}
+
 type model struct {
 	rooms                  map[string]Point
-	links                  [][2]string
-	steps                  [][]string
+	links                  []link
+	steps                  [][]models.Move
 	currStep               int
 	minX, minY, maxX, maxY int
 }
@@ -75,10 +85,10 @@ func (m model) View() string {
 
 	// 1. СНАЧАЛА РИСУЕМ СВЯЗИ (фоновый слой)
 	for _, link := range m.links {
-		p1, ok1 := m.rooms[link[0]]
-		p2, ok2 := m.rooms[link[1]]
+		p1, ok1 := m.rooms[link.from]
+		p2, ok2 := m.rooms[link.to]
 		if ok1 && ok2 {
-			drawConnection(canvas, p1, p2, m.minX, m.minY, scaleX, scaleY)
+			drawConnection(canvas, p1, p2, link.oneWay, m.minX, m.minY, scaleX, scaleY)
 		}
 	}
 
@@ -86,11 +96,14 @@ func (m model) View() string {
 	antsInRooms := make(map[string]string)
 	movesInfo := "Start / Начало"
 	if m.currStep < len(m.steps) && len(m.steps[m.currStep]) > 0 {
-		movesInfo = strings.Join(m.steps[m.currStep], " ")
+		movesInfo = formatter.FormatTurn(m.steps[m.currStep])
+		// Rooms with a capacity above one can hold several ants
+		// Комнаты вместимостью больше одного могут вмещать несколько муравьев
 		for _, move := range m.steps[m.currStep] {
-			parts := strings.Split(move, "-")
-			if len(parts) == 2 {
-				antsInRooms[parts[1]] = parts[0]
+			if ants, ok := antsInRooms[move.To]; ok {
+				antsInRooms[move.To] = fmt.Sprintf("%s,L%d", ants, move.AntID)
+			} else {
+				antsInRooms[move.To] = fmt.Sprintf("L%d", move.AntID)
 			}
 		}
 	}
@@ -144,7 +157,7 @@ func (m model) View() string {
 	return out.String()
 }
 
-func drawConnection(canvas [][]string, p1, p2 Point, minX, minY, scaleX, scaleY int) {
+func drawConnection(canvas [][]string, p1, p2 Point, oneWay bool, minX, minY, scaleX, scaleY int) {
 	// Точки входа туннелей в комнаты (с учетом смещения)
 	x1, y1 := (p1.X-minX)*scaleX+6, (p1.Y-minY)*scaleY+2
 	x2, y2 := (p2.X-minX)*scaleX+6, (p2.Y-minY)*scaleY+2
@@ -156,6 +169,10 @@ func drawConnection(canvas [][]string, p1, p2 Point, minX, minY, scaleX, scaleY
 			// Рисуем точки только там, где еще нет текста
 			if canvas[cy][cx] == " " {
 				canvas[cy][cx] = "·"
+				// Стрелка у конца одностороннего туннеля показывает направление
+				if oneWay && i == steps-2 {
+					canvas[cy][cx] = arrowHead(x2-x1, y2-y1)
+				}
 			}
 		}
 	}
@@ -168,18 +185,37 @@ func main() {
 	}
 	scanner := bufio.NewScanner(os.Stdin)
 	parsingMoves := false
+	// Blank lines after the map separator are turns spent inside long tunnels
+	// Пустые строки после разделителя карты — ходы, проведенные в длинных туннелях
+	blanks := 0
 	for scanner.Scan() {
 		line := scanner.Text()
-		if line == "" || (strings.HasPrefix(line, "#") && !strings.HasPrefix(line, "##")) {
+		if line == "" {
+			blanks++
+			continue
+		}
+		if strings.HasPrefix(line, "#") && !strings.HasPrefix(line, "##") {
 			continue
 		}
 		if strings.HasPrefix(line, "L") {
+			if !parsingMoves && len(m.rooms) > 0 && blanks > 0 {
+				blanks--
+			}
+			for ; blanks > 0; blanks-- {
+				m.steps = append(m.steps, nil)
+			}
 			parsingMoves = true
-			m.steps = append(m.steps, strings.Fields(line))
+			moves, err := parser.ParseTurn(line, len(m.steps)+1)
+			if err != nil {
+				fmt.Printf("Error: %v", err)
+				os.Exit(1)
+			}
+			m.steps = append(m.steps, moves)
 			continue
 		}
+		blanks = 0
 		parts := strings.Fields(line)
-		if !parsingMoves && len(parts) == 3 {
+		if !parsingMoves && len(parts) == 3 && !strings.ContainsAny(parts[0], "->") {
 			var x, y int
 			fmt.Sscanf(parts[1], "%d", &x)
 			fmt.Sscanf(parts[2], "%d", &y)
@@ -196,18 +232,51 @@ func main() {
 			if y > m.maxY {
 				m.maxY = y
 			}
-		} else if !parsingMoves && strings.Contains(line, "-") {
-			l := strings.Split(line, "-")
+		} else if !parsingMoves && strings.ContainsAny(parts[0], "->") {
+			// Tunnel length and width after the link do not matter for drawing
+			// Длина и ширина туннеля после связи не важны для отрисовки
+			l := strings.FieldsFunc(parts[0], func(r rune) bool { return r == '-' || r == '>' })
 			if len(l) == 2 {
-				m.links = append(m.links, [2]string{l[0], l[1]})
+				m.links = append(m.links, link{from: l[0], to: l[1], oneWay: strings.Contains(parts[0], ">")})
 			}
 		}
 	}
 	if len(m.steps) == 0 {
-		m.steps = [][]string{{}}
+		m.steps = [][]models.Move{{}}
 	}
 	if _, err := tea.NewProgram(m).Run(); err != nil {
 		fmt.Printf("Error: %v", err)
 		os.Exit(1)
 	}
 }
+
+// arrowHead picks the arrow closest to the direction (dx, dy) on the canvas.
+func arrowHead(dx, dy int) string {
+	switch {
+	case abs(dx) > 3*abs(dy):
+		if dx > 0 {
+			return "→"
+		}
+		return "←"
+	case 3*abs(dx) < abs(dy):
+		if dy > 0 {
+			return "↓"
+		}
+		return "↑"
+	case dx > 0 && dy > 0:
+		return "↘"
+	case dx > 0:
+		return "↗"
+	case dy > 0:
+		return "↙"
+	default:
+		return "↖"
+	}
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
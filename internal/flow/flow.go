@@ -0,0 +1,131 @@
+// Package flow implements a residual flow network with min-cost augmenting paths.
+// Пакет flow реализует остаточную сеть потоков с увеличивающими путями минимальной стоимости.
+package flow
+
+// Inf is the capacity of edges that can never be saturated.
+// Inf — пропускная способность ребер, которые невозможно насытить.
+const Inf = int(^uint(0) >> 2)
+
+// Edge is a directed arc of the network. Edges are stored in pairs:
+// the arc with an even index is the forward one, index^1 is its residual twin.
+// Edge — направленная дуга сети. Ребра хранятся парами:
+// дуга с четным индексом прямая, индекс^1 — ее остаточная пара.
+type Edge struct {
+	From, To int
+	Capacity int
+	Cost     int
+	Flow     int
+}
+
+// Network is a flow network addressed by integer node indices.
+// Network — сеть потоков с вершинами, заданными целочисленными индексами.
+type Network struct {
+	Edges []Edge
+	Adj   [][]int
+}
+
+// New creates a network with the given number of nodes and no edges.
+// New создает сеть с заданным числом вершин и без ребер.
+func New(nodes int) *Network {
+	return &Network{Adj: make([][]int, nodes)}
+}
+
+// AddNode appends a new node and returns its index.
+// AddNode добавляет новую вершину и возвращает ее индекс.
+func (n *Network) AddNode() int {
+	n.Adj = append(n.Adj, nil)
+	return len(n.Adj) - 1
+}
+
+// AddEdge adds a directed arc with its residual twin and returns the arc index.
+// AddEdge добавляет направленную дугу с остаточной парой и возвращает индекс дуги.
+func (n *Network) AddEdge(from, to, capacity, cost int) int {
+	id := len(n.Edges)
+	n.Edges = append(n.Edges,
+		Edge{From: from, To: to, Capacity: capacity, Cost: cost},
+		Edge{From: to, To: from, Capacity: 0, Cost: -cost},
+	)
+	n.Adj[from] = append(n.Adj[from], id)
+	n.Adj[to] = append(n.Adj[to], id+1)
+	return id
+}
+
+// Residual returns how much more flow the arc can carry.
+// Residual возвращает, сколько еще потока может пропустить дуга.
+func (n *Network) Residual(id int) int {
+	return n.Edges[id].Capacity - n.Edges[id].Flow
+}
+
+// Augment pushes flow along the cheapest residual path from source to sink.
+// At most limit units are pushed; the pushed amount and the path cost are returned.
+// Augment проталкивает поток по самому дешевому остаточному пути от истока к стоку.
+// Проталкивается не более limit единиц; возвращаются объем потока и стоимость пути.
+func (n *Network) Augment(source, sink, limit int) (pushed, cost int, ok bool) {
+	dist := make([]int, len(n.Adj))
+	prev := make([]int, len(n.Adj))
+	inQueue := make([]bool, len(n.Adj))
+	for i := range dist {
+		dist[i] = Inf
+		prev[i] = -1
+	}
+
+	// SPFA: residual twins carry negative costs, so plain Dijkstra does not fit
+	// SPFA: остаточные пары имеют отрицательную стоимость, обычная Дейкстра не подходит
+	dist[source] = 0
+	queue := []int{source}
+	inQueue[source] = true
+	for len(queue) > 0 {
+		v := queue[0]
+		queue = queue[1:]
+		inQueue[v] = false
+		for _, id := range n.Adj[v] {
+			e := n.Edges[id]
+			if n.Residual(id) <= 0 || dist[v]+e.Cost >= dist[e.To] {
+				continue
+			}
+			dist[e.To] = dist[v] + e.Cost
+			prev[e.To] = id
+			if !inQueue[e.To] {
+				inQueue[e.To] = true
+				queue = append(queue, e.To)
+			}
+		}
+	}
+
+	if dist[sink] == Inf {
+		return 0, 0, false
+	}
+
+	pushed = limit
+	for v := sink; v != source; v = n.Edges[prev[v]].From {
+		if r := n.Residual(prev[v]); r < pushed {
+			pushed = r
+		}
+	}
+	for v := sink; v != source; v = n.Edges[prev[v]].From {
+		n.Edges[prev[v]].Flow += pushed
+		n.Edges[prev[v]^1].Flow -= pushed
+	}
+	return pushed, dist[sink], true
+}
+
+// Reachable reports which nodes the source reaches through arcs with residual capacity.
+// After a maximum flow these nodes form the source side of a minimum cut.
+// Reachable сообщает, какие вершины достижимы из истока по дугам с остаточной емкостью.
+// После максимального потока эти вершины образуют сторону истока минимального разреза.
+func (n *Network) Reachable(source int) []bool {
+	seen := make([]bool, len(n.Adj))
+	seen[source] = true
+	stack := []int{source}
+	for len(stack) > 0 {
+		v := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for _, id := range n.Adj[v] {
+			if to := n.Edges[id].To; !seen[to] && n.Residual(id) > 0 {
+				seen[to] = true
+				stack = append(stack, to)
+			}
+		}
+	}
+	return seen
+}
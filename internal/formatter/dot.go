@@ -0,0 +1,67 @@
+package formatter
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"lem-in/internal/models"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// WriteDOT writes the farm as a Graphviz graph: rooms keep their coordinates,
+// starts and ends are highlighted, capacities and tunnel lengths and widths
+// other than one are shown as labels, and one-way tunnels get arrows.
+// WriteDOT записывает ферму в виде графа Graphviz: комнаты сохраняют координаты,
+// старты и финиши выделены, вместимость и длина и ширина туннелей, отличные
+// от единицы, показаны подписями, а односторонние туннели — стрелками.
+func WriteDOT(w io.Writer, farm *models.Farm) error {
+	out := bufio.NewWriter(w)
+	fmt.Fprintln(out, "digraph farm {")
+	fmt.Fprintln(out, "  node [shape=circle];")
+
+	// Rooms live in a map; sort them so the output is stable
+	// Комнаты хранятся в map; сортируем их для стабильного вывода
+	names := make([]string, 0, len(farm.Rooms))
+	for name := range farm.Rooms {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		r := farm.Rooms[name]
+		label := name
+		if r.Capacity > 1 {
+			label = fmt.Sprintf("%s\n×%d", name, r.Capacity)
+		}
+		attrs := fmt.Sprintf("label=%s, pos=\"%d,%d!\"", dotQuote(label), r.X, -r.Y)
+		switch {
+		case slices.Contains(farm.Starts, name) || name == farm.Start:
+			attrs += ", shape=doublecircle, color=green"
+		case slices.Contains(farm.Ends, name) || name == farm.End:
+			attrs += ", shape=doublecircle, color=red"
+		}
+		fmt.Fprintf(out, "  %s [%s];\n", dotQuote(name), attrs)
+	}
+
+	for _, l := range farm.Links {
+		attrs := "dir=none"
+		if l.Directed {
+			attrs = "dir=forward"
+		}
+		if length, width := max(l.Length, 1), max(l.Width, 1); length > 1 || width > 1 {
+			attrs += fmt.Sprintf(", label=\"%d×%d\"", length, width)
+		}
+		fmt.Fprintf(out, "  %s -> %s [%s];\n", dotQuote(l.From), dotQuote(l.To), attrs)
+	}
+	fmt.Fprintln(out, "}")
+	return out.Flush()
+}
+
+// dotQuote returns the text as a quoted DOT string, with line breaks kept as \n.
+// dotQuote возвращает текст в виде строки DOT в кавычках, переводы строк — как \n.
+func dotQuote(text string) string {
+	return `"` + dotEscaper.Replace(text) + `"`
+}
+
+var dotEscaper = strings.NewReplacer(`"`, `\"`, "\n", `\n`)
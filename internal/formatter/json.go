@@ -0,0 +1,149 @@
+package formatter
+
+import (
+	"encoding/json"
+	"io"
+	"iter"
+	"lem-in/internal/models"
+	"lem-in/internal/simulation"
+	"sort"
+)
+
+// Document is the JSON representation of a solved farm.
+// Document — JSON-представление решенной фермы.
+type Document struct {
+	Farm         FarmDocument   `json:"farm"`
+	Paths        [][]string     `json:"paths"`
+	Distribution [][]int        `json:"distribution"`
+	Turns        []TurnDocument `json:"turns"`
+	TurnCount    int            `json:"turn_count"`
+}
+
+// FarmDocument describes the parsed colony. Start and End are the first of Starts and Ends.
+// FarmDocument описывает распарсенную колонию. Start и End — первые из Starts и Ends.
+type FarmDocument struct {
+	Ants      int            `json:"ants"`
+	Start     string         `json:"start"`
+	End       string         `json:"end"`
+	Starts    []string       `json:"starts"`
+	Ends      []string       `json:"ends"`
+	StartAnts map[string]int `json:"start_ants,omitempty"`
+	Rooms     []RoomDocument `json:"rooms"`
+	Links     []LinkDocument `json:"links"`
+}
+
+// RoomDocument is a room with its coordinates and capacity.
+// RoomDocument — комната с координатами и вместимостью.
+type RoomDocument struct {
+	Name     string `json:"name"`
+	X        int    `json:"x"`
+	Y        int    `json:"y"`
+	Capacity int    `json:"capacity"`
+}
+
+// LinkDocument is a tunnel between two rooms with its length in turns and width in ants per turn.
+// A directed tunnel only leads from From to To.
+// LinkDocument — туннель между двумя комнатами с длиной в ходах и шириной в муравьях за ход.
+// Направленный туннель ведет только из From в To.
+type LinkDocument struct {
+	From     string `json:"from"`
+	To       string `json:"to"`
+	Length   int    `json:"length"`
+	Width    int    `json:"width"`
+	Directed bool   `json:"directed"`
+}
+
+// TurnDocument lists the moves made during one turn.
+// TurnDocument перечисляет ходы, сделанные за один ход.
+type TurnDocument struct {
+	Turn  int            `json:"turn"`
+	Moves []MoveDocument `json:"moves"`
+}
+
+// MoveDocument is a single ant entering a room.
+// MoveDocument — переход одного муравья в комнату.
+type MoveDocument struct {
+	Ant  int    `json:"ant"`
+	From string `json:"from,omitempty"`
+	Room string `json:"room"`
+}
+
+// WriteJSON writes the farm, the chosen paths, the ant distribution and every turn as one JSON document.
+// WriteJSON записывает ферму, выбранные пути, распределение муравьев и все ходы одним JSON-документом.
+func WriteJSON(w io.Writer, farm *models.Farm, paths []models.Path, distribution [][]int, turns [][]models.Move) error {
+	doc := Document{
+		Farm:         newFarmDocument(farm),
+		Paths:        make([][]string, len(paths)),
+		Distribution: make([][]int, len(distribution)),
+		Turns:        make([]TurnDocument, len(turns)),
+		TurnCount:    len(turns),
+	}
+	for i, p := range paths {
+		doc.Paths[i] = p.Rooms
+	}
+	// Paths left without ants are written as [] rather than null
+	// Пути без муравьев записываются как [], а не null
+	for i, ants := range distribution {
+		doc.Distribution[i] = append([]int{}, ants...)
+	}
+	for i, moves := range turns {
+		doc.Turns[i] = newTurnDocument(i+1, moves)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(doc)
+}
+
+// WriteFarmJSON writes the farm alone, without a solution, as one JSON document.
+// WriteFarmJSON записывает одну ферму, без решения, одним JSON-документом.
+func WriteFarmJSON(w io.Writer, farm *models.Farm) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(newFarmDocument(farm))
+}
+
+func newFarmDocument(farm *models.Farm) FarmDocument {
+	doc := FarmDocument{
+		Ants:      farm.Ants,
+		Start:     farm.Start,
+		End:       farm.End,
+		Starts:    farm.Starts,
+		Ends:      farm.Ends,
+		StartAnts: farm.StartAnts,
+		Rooms:     make([]RoomDocument, 0, len(farm.Rooms)),
+		Links:     make([]LinkDocument, len(farm.Links)),
+	}
+	for _, r := range farm.Rooms {
+		doc.Rooms = append(doc.Rooms, RoomDocument{Name: r.Name, X: r.X, Y: r.Y, Capacity: max(r.Capacity, 1)})
+	}
+	// Rooms live in a map; sort them so the output is stable
+	// Комнаты хранятся в map; сортируем их для стабильного вывода
+	sort.Slice(doc.Rooms, func(i, j int) bool {
+		return doc.Rooms[i].Name < doc.Rooms[j].Name
+	})
+	for i, l := range farm.Links {
+		doc.Links[i] = LinkDocument{From: l.From, To: l.To, Length: max(l.Length, 1), Width: max(l.Width, 1), Directed: l.Directed}
+	}
+	return doc
+}
+
+func newTurnDocument(turn int, moves []models.Move) TurnDocument {
+	doc := TurnDocument{Turn: turn, Moves: make([]MoveDocument, len(moves))}
+	for i, m := range moves {
+		doc.Moves[i] = MoveDocument{Ant: m.AntID, From: m.From, Room: m.To}
+	}
+	return doc
+}
+
+// WriteNDJSON writes one JSON line per turn as the events arrive.
+// WriteNDJSON записывает по одной JSON-строке на ход по мере поступления событий.
+func WriteNDJSON(w io.Writer, events iter.Seq[simulation.TurnEvent]) error {
+	enc := json.NewEncoder(w)
+	for event := range events {
+		if err := enc.Encode(newTurnDocument(event.Turn, event.Moves)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
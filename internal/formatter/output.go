@@ -3,25 +3,49 @@
 package formatter
 
 import (
+	"bufio"
 	"fmt"
+	"io"
+	"lem-in/internal/models"
+	"os"
+	"strings"
 )
 
 // Print displays the original file content followed by the ant movement steps.
 // Print выводит исходное содержание файла, а затем шаги передвижения муравьев.
-func Print(rawLines []string, moves []string) {
+func Print(rawLines []string, turns [][]models.Move) {
+	Write(os.Stdout, rawLines, turns)
+}
+
+// Write writes the original file content followed by the ant movement steps to w.
+// Write записывает в w исходное содержание файла, а затем шаги передвижения муравьев.
+func Write(w io.Writer, rawLines []string, turns [][]models.Move) error {
+	out := bufio.NewWriter(w)
+
 	// Output original farm data
 	// Выводим оригинальные данные фермы
 	for _, line := range rawLines {
-		fmt.Println(line)
+		fmt.Fprintln(out, line)
 	}
 
 	// Print a newline between the farm data and the simulation results
 	// Печатаем пустую строку между данными фермы и результатами симуляции
-	fmt.Println()
+	fmt.Fprintln(out)
 
 	// Output ant moves step by step
 	// Выводим ходы муравьев шаг за шагом
-	for _, move := range moves {
-		fmt.Println(move)
+	for _, moves := range turns {
+		fmt.Fprintln(out, FormatTurn(moves))
+	}
+	return out.Flush()
+}
+
+// FormatTurn renders the moves of one turn as a line of "Lx-room" entries.
+// FormatTurn выводит ходы одного хода в виде строки записей "Lx-room".
+func FormatTurn(moves []models.Move) string {
+	parts := make([]string, len(moves))
+	for i, m := range moves {
+		parts[i] = fmt.Sprintf("L%d-%s", m.AntID, m.To)
 	}
+	return strings.Join(parts, " ")
 }
@@ -0,0 +1,274 @@
+// Package generator builds random ant farm maps of typical shapes. The same
+// profile, seed and options always produce the same map.
+// Пакет generator строит случайные карты муравьиных ферм типичных форм. Один и тот же
+// профиль, зерно и параметры всегда дают одну и ту же карту.
+package generator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"sort"
+	"strings"
+
+	"lem-in/internal/graph"
+	"lem-in/internal/models"
+	"lem-in/internal/parser"
+	"lem-in/internal/solver"
+)
+
+// Options selects the shape of a map. Zero Ants and Size take the profile defaults;
+// Size means the corridor length, the number of routes, the grid side or the number of rooms.
+// Options задает форму карты. Нулевые Ants и Size берутся из профиля; Size — это длина
+// коридора, число маршрутов, сторона сетки или число комнат.
+type Options struct {
+	Profile string
+	Seed    uint64
+	Ants    int
+	Size    int
+}
+
+// ErrUnproven is returned when the optimal number of turns could not be proven in time.
+// ErrUnproven возвращается, когда оптимальное число ходов не удалось доказать вовремя.
+var ErrUnproven = errors.New("optimal number of turns not proven")
+
+// profile строит карту заданного размера и знает размеры по умолчанию
+type profile struct {
+	build func(b *builder, size int)
+	size  int
+	ants  int
+}
+
+var profiles = map[string]profile{
+	"corridor":      {buildCorridor, 10, 10},
+	"parallel":      {buildParallel, 4, 20},
+	"grid":          {buildGrid, 6, 20},
+	"big":           {buildBig, 3000, 100},
+	"superposition": {buildSuperposition, 3, 30},
+}
+
+// Profiles lists the available profiles in alphabetical order.
+// Profiles перечисляет доступные профили в алфавитном порядке.
+func Profiles() []string {
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Generate returns the lines of a map file for the given options.
+// Generate возвращает строки файла карты для заданных параметров.
+func Generate(opts Options) ([]string, error) {
+	p, ok := profiles[opts.Profile]
+	if !ok {
+		return nil, fmt.Errorf("unknown profile %q, available: %s", opts.Profile, strings.Join(Profiles(), ", "))
+	}
+	if opts.Size <= 0 {
+		opts.Size = p.size
+	}
+	if opts.Ants <= 0 {
+		opts.Ants = p.ants
+	}
+
+	b := &builder{
+		rng:   rand.New(rand.NewPCG(opts.Seed, 0)),
+		taken: make(map[[2]int]bool),
+	}
+	p.build(b, opts.Size)
+	return b.lines(opts.Ants), nil
+}
+
+// ExpectedTurns proves the optimal number of turns for a map: either the fast
+// solver reaches the lower bound, or the exact solver finishes before ctx is done.
+// When ctx ends first it returns ErrUnproven; other errors are returned unchanged.
+// ExpectedTurns доказывает оптимальное число ходов для карты: либо быстрая стратегия
+// достигает нижней границы, либо точная стратегия успевает до завершения ctx.
+// Если ctx завершается раньше, возвращает ErrUnproven; прочие ошибки возвращаются как есть.
+func ExpectedTurns(ctx context.Context, lines []string) (int, error) {
+	farm, err := parser.ParseReader(strings.NewReader(strings.Join(lines, "\n")))
+	if err != nil {
+		return 0, err
+	}
+	g := graph.Build(farm)
+	g.Prune()
+
+	bound, err := solver.LowerBound(g, farm.Ants)
+	if err != nil {
+		return 0, err
+	}
+	fast, err := solver.MaxFlow{}.Solve(ctx, g, farm.Ants)
+	if err == nil && !fast.Partial && fast.Turns == bound.Turns {
+		return fast.Turns, nil
+	}
+	exact, err := solver.Exact{}.Solve(ctx, g, farm.Ants)
+	if exact.Partial || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return 0, ErrUnproven
+	}
+	if err != nil {
+		return 0, err
+	}
+	return exact.Turns, nil
+}
+
+// builder накапливает комнаты и туннели карты; координаты комнат не повторяются
+type builder struct {
+	rng        *rand.Rand
+	rooms      []models.Room
+	taken      map[[2]int]bool
+	links      []models.Link
+	start, end string
+	named      int // число промежуточных комнат
+}
+
+// room добавляет комнату с ближайшими свободными координатами над (x, y)
+func (b *builder) room(name string, x, y int) string {
+	for b.taken[[2]int{x, y}] {
+		y++
+	}
+	b.taken[[2]int{x, y}] = true
+	b.rooms = append(b.rooms, models.Room{Name: name, X: x, Y: y})
+	return name
+}
+
+// next добавляет промежуточную комнату со следующим свободным именем
+func (b *builder) next(x, y int) string {
+	b.named++
+	return b.room(fmt.Sprintf("r%d", b.named), x, y)
+}
+
+// link соединяет две комнаты двусторонним туннелем
+func (b *builder) link(from, to string) {
+	b.links = append(b.links, models.Link{From: from, To: to})
+}
+
+// chain прокладывает маршрут из length новых комнат между from и to в строке y
+func (b *builder) chain(from, to string, length, x, y int) []string {
+	rooms := make([]string, length)
+	prev := from
+	for i := range rooms {
+		rooms[i] = b.next(x+i, y)
+		b.link(prev, rooms[i])
+		prev = rooms[i]
+	}
+	b.link(prev, to)
+	return rooms
+}
+
+// lines записывает карту в формате входного файла
+func (b *builder) lines(ants int) []string {
+	lines := []string{fmt.Sprint(ants)}
+	for _, r := range b.rooms {
+		switch r.Name {
+		case b.start:
+			lines = append(lines, "##start")
+		case b.end:
+			lines = append(lines, "##end")
+		}
+		lines = append(lines, fmt.Sprintf("%s %d %d", r.Name, r.X, r.Y))
+	}
+	for _, l := range b.links {
+		lines = append(lines, l.From+"-"+l.To)
+	}
+	return lines
+}
+
+// terminals добавляет старт и финиш по краям карты шириной width
+func (b *builder) terminals(width int) {
+	b.start = b.room("start", 0, 0)
+	b.end = b.room("end", width+1, 0)
+}
+
+// buildCorridor — один длинный коридор: муравьи идут строго друг за другом
+func buildCorridor(b *builder, length int) {
+	b.terminals(length)
+	b.chain(b.start, b.end, length, 1, 0)
+}
+
+// buildParallel — несколько непересекающихся маршрутов случайной длины
+func buildParallel(b *builder, routes int) {
+	lengths := make([]int, routes)
+	width := 0
+	for i := range lengths {
+		lengths[i] = 1 + b.rng.IntN(2*routes)
+		width = max(width, lengths[i])
+	}
+	b.terminals(width)
+	for i, length := range lengths {
+		b.chain(b.start, b.end, length, 1, i+1)
+	}
+}
+
+// buildGrid — квадратная сетка с выпавшими туннелями; нижний ряд всегда цел,
+// поэтому путь от старта к финишу есть всегда
+func buildGrid(b *builder, side int) {
+	b.terminals(side)
+	cells := make([][]string, side)
+	for y := range cells {
+		cells[y] = make([]string, side)
+		for x := range cells[y] {
+			cells[y][x] = b.next(x+1, y)
+		}
+	}
+	for y := range side {
+		if y == 0 || b.rng.IntN(2) == 0 {
+			b.link(b.start, cells[y][0])
+		}
+		if y == 0 || b.rng.IntN(2) == 0 {
+			b.link(cells[y][side-1], b.end)
+		}
+		for x := range side {
+			if x+1 < side && (y == 0 || b.rng.IntN(5) > 0) {
+				b.link(cells[y][x], cells[y][x+1])
+			}
+			if y+1 < side && b.rng.IntN(5) > 0 {
+				b.link(cells[y][x], cells[y+1][x])
+			}
+		}
+	}
+}
+
+// buildBig — несколько непересекающихся маршрутов среди тысяч лишних комнат:
+// каждая лишняя комната соединена не более чем с одной из предыдущих, поэтому они
+// образуют тупиковые деревья и острова и не меняют оптимального числа ходов
+func buildBig(b *builder, rooms int) {
+	routes := 4 + b.rng.IntN(5)
+	b.terminals(40)
+	var backbone []string
+	for i := range routes {
+		backbone = append(backbone, b.chain(b.start, b.end, 5+b.rng.IntN(30), 1, i+1)...)
+	}
+
+	// Лишняя комната присоединяется к случайной предыдущей; изредка она остается
+	// одна или цепляется к другой лишней комнате, образуя острова
+	pool := slices.Clone(backbone)
+	first := len(pool)
+	for i := len(b.rooms); i < rooms; i++ {
+		room := b.next(i%100, 50+i/100)
+		switch {
+		case len(pool) > first && b.rng.IntN(10) == 0:
+			b.link(room, pool[first+b.rng.IntN(len(pool)-first)])
+		case b.rng.IntN(10) > 0:
+			b.link(room, pool[b.rng.IntN(len(pool))])
+		}
+		pool = append(pool, room)
+	}
+}
+
+// buildSuperposition — маршруты одинаковой длины с короткими перемычками между
+// соседями: кратчайший путь занимает сразу два маршрута и мешает остальным
+func buildSuperposition(b *builder, routes int) {
+	length := 4 + b.rng.IntN(4)
+	b.terminals(length)
+	rows := make([][]string, routes)
+	for i := range rows {
+		rows[i] = b.chain(b.start, b.end, length, 1, 2*i+1)
+	}
+	for i := 0; i+1 < routes; i++ {
+		from := b.rng.IntN(length - 2)
+		b.link(rows[i][from], rows[i+1][from+2])
+	}
+}
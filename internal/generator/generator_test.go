@@ -0,0 +1,62 @@
+package generator_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"lem-in/internal/generator"
+	"lem-in/internal/graph"
+	"lem-in/internal/parser"
+	"lem-in/internal/solver"
+)
+
+func TestSameSeedSameMap(t *testing.T) {
+	for _, profile := range generator.Profiles() {
+		opts := generator.Options{Profile: profile, Seed: 7}
+		first, err := generator.Generate(opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		second, _ := generator.Generate(opts)
+		if !slices.Equal(first, second) {
+			t.Errorf("%s: seed 7 gave two different maps", profile)
+		}
+	}
+}
+
+func TestEveryProfileHasAPath(t *testing.T) {
+	for _, profile := range generator.Profiles() {
+		for seed := range uint64(3) {
+			lines, err := generator.Generate(generator.Options{Profile: profile, Seed: seed})
+			if err != nil {
+				t.Fatal(err)
+			}
+			farm, err := parser.ParseReader(strings.NewReader(strings.Join(lines, "\n")))
+			if err != nil {
+				t.Fatalf("%s seed %d: %v", profile, seed, err)
+			}
+			if _, err := solver.LowerBound(graph.Build(farm), farm.Ants); err != nil {
+				t.Errorf("%s seed %d: %v", profile, seed, err)
+			}
+		}
+	}
+}
+
+func TestExpectedTurns(t *testing.T) {
+	lines, _ := generator.Generate(generator.Options{Profile: "grid", Seed: 1, Ants: 7, Size: 3})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if turns, err := generator.ExpectedTurns(ctx, lines); err != nil || turns == 0 {
+		t.Errorf("ExpectedTurns = %d, %v", turns, err)
+	}
+
+	// A map without a path is not merely unproven
+	noPath := []string{"1", "##start", "s 0 0", "##end", "e 1 0", "a 2 0", "s-a"}
+	if _, err := generator.ExpectedTurns(ctx, noPath); !errors.Is(err, solver.ErrNoPath) {
+		t.Errorf("map without a path: got %v, want ErrNoPath", err)
+	}
+}
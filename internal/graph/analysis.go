@@ -0,0 +1,316 @@
+package graph
+
+import (
+	"lem-in/internal/flow"
+	"sort"
+)
+
+// Cut is a minimum cut between the starts and the ends: the rooms and tunnels
+// that together limit how many ants can cross the farm during one turn.
+// Cut — минимальный разрез между стартами и финишами: комнаты и туннели,
+// которые вместе ограничивают, сколько муравьев может пересечь ферму за один ход.
+type Cut struct {
+	Throughput int // ants per turn, the sum of the capacities in the cut
+	Rooms      []string
+	Tunnels    []string // tunnel keys
+}
+
+// MinCut finds a minimum cut by maximum flow on the graph with every room split
+// into an entrance and an exit: a room limits the flow by its capacity, a tunnel by its width.
+// MinCut находит минимальный разрез через максимальный поток в графе, где каждая комната
+// расщеплена на вход и выход: комната ограничивает поток вместимостью, туннель — шириной.
+func (g *Graph) MinCut() Cut {
+	names := g.sortedRooms()
+	index := make(map[string]int, len(names))
+	for i, name := range names {
+		index[name] = i
+	}
+
+	// Capacities are scaled so that every tunnel costs a little more than a room
+	// of the same capacity: among equal cuts the one made of rooms wins
+	// Емкости масштабируются так, чтобы туннель стоил чуть больше комнаты той же
+	// вместимости: из равных разрезов выбирается состоящий из комнат
+	scale := len(g.Tunnels) + 1
+
+	// Room i is the arc 2i -> 2i+1; starts and ends are unlimited
+	// Комната i — дуга 2i -> 2i+1; старты и финиши не ограничены
+	source, sink := 2*len(names), 2*len(names)+1
+	net := flow.New(2*len(names) + 2)
+	owner := make(map[int]string)
+	for i, name := range names {
+		capacity := g.Capacities[name] * scale
+		if g.IsStart(name) || g.IsEnd(name) {
+			capacity = flow.Inf
+		}
+		owner[net.AddEdge(2*i, 2*i+1, capacity, 0)] = name
+	}
+	tunnels := make(map[int]string)
+	for _, u := range names {
+		if g.IsEnd(u) {
+			continue
+		}
+		for _, v := range g.AdjacencyList[u] {
+			if g.IsStart(v) {
+				continue
+			}
+			t := g.Tunnel(u, v)
+			tunnels[net.AddEdge(2*index[u]+1, 2*index[v], t.Width*scale+1, 0)] = t.Key
+		}
+	}
+	for _, s := range g.Starts {
+		net.AddEdge(source, 2*index[s]+1, flow.Inf, 0)
+	}
+	for _, e := range g.Ends {
+		net.AddEdge(2*index[e], sink, flow.Inf, 0)
+	}
+
+	var cut Cut
+	total := 0
+	for {
+		pushed, _, ok := net.Augment(source, sink, flow.Inf)
+		if !ok {
+			break
+		}
+		total += pushed
+	}
+	cut.Throughput = total / scale
+
+	// Saturated arcs leaving the side reachable from the source form the cut
+	// Насыщенные дуги, выходящие из достижимой от истока части, образуют разрез
+	reached := net.Reachable(source)
+	seen := make(map[string]bool)
+	for id := 0; id < len(net.Edges); id += 2 {
+		e := net.Edges[id]
+		if !reached[e.From] || reached[e.To] {
+			continue
+		}
+		if name, ok := owner[id]; ok {
+			cut.Rooms = append(cut.Rooms, name)
+		} else if key, ok := tunnels[id]; ok && !seen[key] {
+			seen[key] = true
+			cut.Tunnels = append(cut.Tunnels, key)
+		}
+	}
+	sort.Strings(cut.Rooms)
+	sort.Strings(cut.Tunnels)
+	return cut
+}
+
+// ArticulationPoints lists the rooms whose removal splits the farm into more
+// pieces, ignoring the direction of one-way tunnels.
+// ArticulationPoints перечисляет комнаты, удаление которых разбивает ферму
+// на большее число частей, без учета направления односторонних туннелей.
+func (g *Graph) ArticulationPoints() []string {
+	var points []string
+	g.walkBlocks(func(room string) {
+		points = append(points, room)
+	}, nil)
+	sort.Strings(points)
+	return points
+}
+
+// Bridges lists the keys of the tunnels whose removal splits the farm into more
+// pieces, ignoring the direction of one-way tunnels.
+// Bridges перечисляет ключи туннелей, удаление которых разбивает ферму
+// на большее число частей, без учета направления односторонних туннелей.
+func (g *Graph) Bridges() []string {
+	var bridges []string
+	g.walkBlocks(nil, func(u, v string) {
+		bridges = append(bridges, g.tunnelBetween(u, v).Key)
+	})
+	sort.Strings(bridges)
+	return bridges
+}
+
+// Unreachable lists the rooms no start leads to.
+// Unreachable перечисляет комнаты, в которые не ведет ни один старт.
+func (g *Graph) Unreachable() []string {
+	forward := g.reach(g.Starts, g.AdjacencyList)
+	var rooms []string
+	for _, name := range g.sortedRooms() {
+		if !forward[name] {
+			rooms = append(rooms, name)
+		}
+	}
+	return rooms
+}
+
+// DeadEnds lists the reachable rooms that cannot lie on a simple path from
+// a start to an end: rooms no end can be reached from, and branches that only
+// lead back the way an ant came in.
+// DeadEnds перечисляет достижимые комнаты, которые не могут лежать на простом
+// пути от старта к финишу: комнаты, из которых не дойти до финиша, и ветви,
+// ведущие только обратно.
+func (g *Graph) DeadEnds() []string {
+	forward := g.reach(g.Starts, g.AdjacencyList)
+	backward := g.reach(g.Ends, g.reversed())
+
+	// Rooms that survive are peeled while they have a single neighbour left
+	// Оставшиеся комнаты отсекаются, пока у них остается один сосед
+	alive := make(map[string]bool)
+	for name := range g.Rooms {
+		alive[name] = forward[name] && backward[name]
+	}
+	neighbours := g.undirected()
+	degree := make(map[string]int)
+	var queue []string
+	for name := range g.Rooms {
+		if !alive[name] {
+			continue
+		}
+		for _, next := range neighbours[name] {
+			if alive[next] {
+				degree[name]++
+			}
+		}
+		if degree[name] <= 1 && !g.IsStart(name) && !g.IsEnd(name) {
+			queue = append(queue, name)
+		}
+	}
+	for len(queue) > 0 {
+		room := queue[0]
+		queue = queue[1:]
+		if !alive[room] {
+			continue
+		}
+		alive[room] = false
+		for _, next := range neighbours[room] {
+			if !alive[next] {
+				continue
+			}
+			degree[next]--
+			if degree[next] <= 1 && !g.IsStart(next) && !g.IsEnd(next) {
+				queue = append(queue, next)
+			}
+		}
+	}
+
+	var rooms []string
+	for _, name := range g.sortedRooms() {
+		if forward[name] && !alive[name] && !g.IsStart(name) && !g.IsEnd(name) {
+			rooms = append(rooms, name)
+		}
+	}
+	return rooms
+}
+
+// walkBlocks runs Tarjan's depth-first search over the undirected view of the
+// farm and reports every articulation point and every bridge once.
+// walkBlocks выполняет поиск в глубину Тарьяна по неориентированному виду
+// фермы и сообщает о каждой точке сочленения и каждом мосте один раз.
+func (g *Graph) walkBlocks(point func(room string), bridge func(u, v string)) {
+	neighbours := g.undirected()
+	order := make(map[string]int)
+	low := make(map[string]int)
+
+	var visit func(room, parent string)
+	visit = func(room, parent string) {
+		order[room] = len(order) + 1
+		low[room] = order[room]
+		children, isPoint := 0, false
+		for _, next := range neighbours[room] {
+			if next == parent {
+				continue
+			}
+			if order[next] != 0 {
+				low[room] = min(low[room], order[next])
+				continue
+			}
+			children++
+			visit(next, room)
+			low[room] = min(low[room], low[next])
+			if parent != "" && low[next] >= order[room] {
+				isPoint = true
+			}
+			if low[next] > order[room] && bridge != nil {
+				bridge(room, next)
+			}
+		}
+		if parent == "" && children > 1 {
+			isPoint = true
+		}
+		if isPoint && point != nil {
+			point(room)
+		}
+	}
+	for _, name := range g.sortedRooms() {
+		if order[name] == 0 {
+			visit(name, "")
+		}
+	}
+}
+
+// undirected returns the neighbours of every room regardless of tunnel direction.
+// undirected возвращает соседей каждой комнаты без учета направления туннелей.
+func (g *Graph) undirected() map[string][]string {
+	neighbours := make(map[string][]string, len(g.Rooms))
+	linked := make(map[[2]string]bool)
+	for _, u := range g.sortedRooms() {
+		for _, v := range g.AdjacencyList[u] {
+			pair := [2]string{min(u, v), max(u, v)}
+			if linked[pair] {
+				continue
+			}
+			linked[pair] = true
+			neighbours[u] = append(neighbours[u], v)
+			neighbours[v] = append(neighbours[v], u)
+		}
+	}
+	return neighbours
+}
+
+// reversed returns the adjacency list with every tunnel turned around.
+// reversed возвращает список смежности с развернутыми туннелями.
+func (g *Graph) reversed() map[string][]string {
+	reversed := make(map[string][]string, len(g.Rooms))
+	for u, next := range g.AdjacencyList {
+		for _, v := range next {
+			reversed[v] = append(reversed[v], u)
+		}
+	}
+	return reversed
+}
+
+// reach marks the rooms reachable from the given rooms along the adjacency list.
+// reach отмечает комнаты, достижимые из данных комнат по списку смежности.
+func (g *Graph) reach(from []string, adjacency map[string][]string) map[string]bool {
+	seen := make(map[string]bool)
+	stack := make([]string, 0, len(from))
+	for _, room := range from {
+		if g.Rooms[room] && !seen[room] {
+			seen[room] = true
+			stack = append(stack, room)
+		}
+	}
+	for len(stack) > 0 {
+		room := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for _, next := range adjacency[room] {
+			if !seen[next] {
+				seen[next] = true
+				stack = append(stack, next)
+			}
+		}
+	}
+	return seen
+}
+
+// tunnelBetween returns the tunnel linking two rooms in either direction.
+// tunnelBetween возвращает туннель, соединяющий две комнаты в любом направлении.
+func (g *Graph) tunnelBetween(u, v string) Tunnel {
+	if _, ok := g.Tunnels[OneWayKey(v, u)]; ok {
+		return g.Tunnel(v, u)
+	}
+	return g.Tunnel(u, v)
+}
+
+// sortedRooms returns the room names in alphabetical order.
+// sortedRooms возвращает имена комнат в алфавитном порядке.
+func (g *Graph) sortedRooms() []string {
+	names := make([]string, 0, len(g.Rooms))
+	for name := range g.Rooms {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
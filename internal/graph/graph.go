@@ -4,16 +4,115 @@ package graph
 
 import (
 	"lem-in/internal/models"
-	"strings"
+	"slices"
 )
 
 // Graph represents the ant colony as an adjacency list for efficient pathfinding.
+// One-way tunnels appear only in the adjacency list of the room they lead from.
 // Graph представляет муравьиную колонию в виде списка смежности для эффективного поиска путей.
+// Односторонние туннели есть только в списке смежности комнаты, из которой они ведут.
 type Graph struct {
 	Rooms         map[string]bool
 	Start         string
 	End           string
 	AdjacencyList map[string][]string
+	// Starts and Ends list every start and end room; Start and End are the first of them.
+	// StartAnts holds the number of ants that must leave from a start, when fixed.
+	// Starts и Ends перечисляют все стартовые и финишные комнаты; Start и End — первые из них.
+	// StartAnts хранит число муравьев, которые должны выйти из старта, если оно задано.
+	Starts    []string
+	Ends      []string
+	StartAnts map[string]int
+	// Capacities holds how many ants each room can contain at once (at least 1).
+	// Start and End are unlimited regardless of this value.
+	// Capacities хранит, сколько муравьев вмещает каждая комната (не менее 1).
+	// Start и End не ограничены независимо от этого значения.
+	Capacities map[string]int
+	// Tunnels holds every tunnel by its key.
+	// Tunnels хранит каждый туннель по его ключу.
+	Tunnels map[string]Tunnel
+}
+
+// Tunnel describes a link: Length is how many turns an ant spends crossing it,
+// Width is how many ants may enter it during one turn. Key identifies the tunnel:
+// TunnelKey for two-way tunnels and OneWayKey for one-way ones.
+// Tunnel описывает связь: Length — сколько ходов муравей проводит в туннеле,
+// Width — сколько муравьев может войти в него за один ход. Key определяет туннель:
+// TunnelKey для двусторонних туннелей и OneWayKey для односторонних.
+type Tunnel struct {
+	Key    string
+	Length int
+	Width  int
+}
+
+// TunnelKey returns a key that does not depend on the direction of the tunnel.
+// TunnelKey возвращает ключ, не зависящий от направления туннеля.
+func TunnelKey(r1, r2 string) string {
+	if r1 > r2 {
+		return r2 + "-" + r1
+	}
+	return r1 + "-" + r2
+}
+
+// OneWayKey returns the key of a one-way tunnel leading from one room to another.
+// OneWayKey возвращает ключ одностороннего туннеля, ведущего из одной комнаты в другую.
+func OneWayKey(from, to string) string {
+	return from + ">" + to
+}
+
+// Tunnel returns the tunnel an ant takes to go from r1 to r2.
+// Tunnel возвращает туннель, по которому муравей идет из r1 в r2.
+func (g *Graph) Tunnel(r1, r2 string) Tunnel {
+	if t, ok := g.Tunnels[TunnelKey(r1, r2)]; ok {
+		return t
+	}
+	if t, ok := g.Tunnels[OneWayKey(r1, r2)]; ok {
+		return t
+	}
+	return Tunnel{Key: TunnelKey(r1, r2), Length: 1, Width: 1}
+}
+
+// AntGroup is a range of ant IDs that may leave from any of the given starts.
+// AntGroup — диапазон ID муравьев, которые могут выйти из любого из данных стартов.
+type AntGroup struct {
+	Starts []string
+	First  int // ID of the first ant in the group
+	Count  int
+}
+
+// AntGroups splits the ants between the starts: the ants of every start with
+// a fixed count come first, in input order, and the rest may leave from any other start.
+// AntGroups делит муравьев между стартами: сначала, в порядке объявления, идут
+// муравьи стартов с заданным числом, остальные могут выйти из любого другого старта.
+func (g *Graph) AntGroups(antCount int) []AntGroup {
+	var groups []AntGroup
+	free := AntGroup{First: 1, Count: antCount}
+	for _, s := range g.Starts {
+		count, fixed := g.StartAnts[s]
+		if !fixed {
+			free.Starts = append(free.Starts, s)
+			continue
+		}
+		groups = append(groups, AntGroup{Starts: []string{s}, First: free.First, Count: count})
+		free.First += count
+		free.Count -= count
+	}
+	if len(free.Starts) > 0 {
+		groups = append(groups, free)
+	}
+	return groups
+}
+
+// IsStart reports whether ants may begin in the room.
+// IsStart сообщает, могут ли муравьи начинать путь в комнате.
+func (g *Graph) IsStart(room string) bool {
+	return slices.Contains(g.Starts, room)
+}
+
+// IsEnd reports whether the room is a destination for the ants.
+// IsEnd сообщает, является ли комната пунктом назначения муравьев.
+func (g *Graph) IsEnd(room string) bool {
+	return slices.Contains(g.Ends, room)
 }
 
 // Build creates a graph structure from the Farm data provided by the parser.
@@ -24,13 +123,27 @@ func Build(farm *models.Farm) *Graph {
 		Start:         farm.Start,
 		End:           farm.End,
 		AdjacencyList: make(map[string][]string),
+		Capacities:    make(map[string]int),
+		Tunnels:       make(map[string]Tunnel),
+		Starts:        farm.Starts,
+		Ends:          farm.Ends,
+		StartAnts:     farm.StartAnts,
+	}
+	// Farms built by hand may only set Start and End
+	// Фермы, собранные вручную, могут задавать только Start и End
+	if len(g.Starts) == 0 {
+		g.Starts = []string{farm.Start}
+	}
+	if len(g.Ends) == 0 {
+		g.Ends = []string{farm.End}
 	}
 
 	// Initialize rooms in the adjacency list
 	// Инициализируем комнаты в списке смежности
-	for name := range farm.Rooms {
+	for name, room := range farm.Rooms {
 		g.Rooms[name] = true
 		g.AdjacencyList[name] = make([]string, 0)
+		g.Capacities[name] = max(room.Capacity, 1)
 	}
 
 	// Add links between rooms
@@ -38,11 +151,7 @@ func Build(farm *models.Farm) *Graph {
 	addedLinks := make(map[string]map[string]bool)
 
 	for _, link := range farm.Links {
-		parts := strings.Split(link, "-")
-		if len(parts) != 2 {
-			continue
-		}
-		u, v := parts[0], parts[1]
+		u, v := link.From, link.To
 
 		// Ensure both rooms exist and aren't linking to themselves
 		// Проверяем, что обе комнаты существуют и связь не ведет к самой себе
@@ -59,11 +168,25 @@ func Build(farm *models.Farm) *Graph {
 			addedLinks[v] = make(map[string]bool)
 		}
 
-		if !addedLinks[u][v] {
+		tunnel := Tunnel{Key: TunnelKey(u, v), Length: max(link.Length, 1), Width: max(link.Width, 1)}
+		if link.Directed {
+			// A one-way tunnel only leads from u to v
+			// Односторонний туннель ведет только из u в v
+			if !addedLinks[u][v] {
+				tunnel.Key = OneWayKey(u, v)
+				g.AdjacencyList[u] = append(g.AdjacencyList[u], v)
+				addedLinks[u][v] = true
+				g.Tunnels[tunnel.Key] = tunnel
+			}
+			continue
+		}
+
+		if !addedLinks[u][v] && !addedLinks[v][u] {
 			g.AdjacencyList[u] = append(g.AdjacencyList[u], v)
 			g.AdjacencyList[v] = append(g.AdjacencyList[v], u)
 			addedLinks[u][v] = true
 			addedLinks[v][u] = true
+			g.Tunnels[tunnel.Key] = tunnel
 		}
 	}
 
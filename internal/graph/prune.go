@@ -0,0 +1,60 @@
+package graph
+
+import "sort"
+
+// PruneReport lists the rooms and tunnel keys removed by Prune.
+// PruneReport перечисляет комнаты и ключи туннелей, удаленные Prune.
+type PruneReport struct {
+	Rooms   []string
+	Tunnels []string
+}
+
+// Prune removes the rooms that cannot lie on any simple path from a start to
+// an end, so that solvers do not explore them: rooms no start leads to, rooms
+// no end can be reached from and dead-end branches. Starts and ends are kept.
+// Prune удаляет комнаты, которые не могут лежать ни на одном простом пути от
+// старта к финишу, чтобы стратегии их не исследовали: комнаты, в которые не ведет
+// ни один старт, из которых не дойти до финиша, и тупиковые ветви. Старты и финиши остаются.
+func (g *Graph) Prune() PruneReport {
+	var report PruneReport
+	removed := make(map[string]bool)
+	for _, name := range append(g.Unreachable(), g.DeadEnds()...) {
+		if !removed[name] && !g.IsStart(name) && !g.IsEnd(name) {
+			removed[name] = true
+			report.Rooms = append(report.Rooms, name)
+		}
+	}
+	if len(removed) == 0 {
+		return report
+	}
+	sort.Strings(report.Rooms)
+
+	// Every tunnel appears in the adjacency list of the room it leads from
+	// Каждый туннель есть в списке смежности комнаты, из которой он ведет
+	for u, next := range g.AdjacencyList {
+		for _, v := range next {
+			key := g.Tunnel(u, v).Key
+			if _, ok := g.Tunnels[key]; ok && (removed[u] || removed[v]) {
+				delete(g.Tunnels, key)
+				report.Tunnels = append(report.Tunnels, key)
+			}
+		}
+	}
+	sort.Strings(report.Tunnels)
+
+	for name := range removed {
+		delete(g.Rooms, name)
+		delete(g.AdjacencyList, name)
+		delete(g.Capacities, name)
+	}
+	for name, next := range g.AdjacencyList {
+		kept := next[:0]
+		for _, v := range next {
+			if !removed[v] {
+				kept = append(kept, v)
+			}
+		}
+		g.AdjacencyList[name] = kept
+	}
+	return report
+}
@@ -3,10 +3,13 @@
 package models
 
 // Room represents a node in the colony with coordinates.
+// Capacity is how many ants the room holds at once; zero means one.
 // Room представляет собой узел колонии с координатами.
+// Capacity — сколько муравьев комната вмещает одновременно; ноль означает одного.
 type Room struct {
-	Name string
-	X, Y int
+	Name     string
+	X, Y     int
+	Capacity int
 }
 
 // Path represents a sequence of rooms from start to end.
@@ -27,13 +30,42 @@ type Ant struct {
 	Finished  bool
 }
 
+// Link represents a tunnel between two rooms.
+// Length is how many turns an ant spends crossing it and Width is how many
+// ants may enter it during one turn; zero means one.
+// A Directed link can only be crossed from From to To.
+// Link представляет туннель между двумя комнатами.
+// Length — сколько ходов муравей проводит в туннеле, Width — сколько муравьев
+// может войти в него за один ход; ноль означает один.
+// По направленной (Directed) связи можно пройти только из From в To.
+type Link struct {
+	From, To      string
+	Length, Width int
+	Directed      bool
+}
+
+// Move represents a single ant passing through a tunnel during a turn.
+// Move представляет переход одного муравья по туннелю за ход.
+type Move struct {
+	AntID    int
+	From, To string
+	Turn     int
+}
+
 // Farm represents the entire colony configuration.
+// Start and End are the first of Starts and Ends; several of them are only
+// accepted by the parser in extended mode.
 // Farm представляет полную конфигурацию колонии.
+// Start и End — первые из Starts и Ends; несколько стартов и финишей
+// парсер принимает только в расширенном режиме.
 type Farm struct {
-	Ants     int
-	Rooms    map[string]*Room
-	Start    string
-	End      string
-	Links    []string // Raw links like "A-B"
-	RawLines []string // Original file content for output
+	Ants      int
+	Rooms     map[string]*Room
+	Start     string
+	End       string
+	Starts    []string       // Every start room in input order
+	Ends      []string       // Every end room in input order
+	StartAnts map[string]int // Ants that must leave from a "##start N" room
+	Links     []Link         // Validated links in input order
+	RawLines  []string       // Original file content for output
 }
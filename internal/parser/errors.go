@@ -0,0 +1,164 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ErrorKind classifies what is wrong with the input.
+// ErrorKind классифицирует, что не так с входными данными.
+type ErrorKind int
+
+// Error kinds reported by the parser.
+// Виды ошибок, о которых сообщает парсер.
+const (
+	KindFile ErrorKind = iota
+	KindAntCount
+	KindRoomFormat
+	KindRoomName
+	KindCoordinates
+	KindDuplicateRoom
+	KindDuplicateCoordinates
+	KindMultipleStart
+	KindMultipleEnd
+	KindLinkFormat
+	KindMissingStart
+	KindMissingEnd
+	KindNoLinks
+	KindUnknownRoom
+	KindSelfLink
+	KindDuplicateLink
+	KindRoomAfterLinks
+	KindMoveFormat
+	KindCapacity
+	KindTunnel
+	KindStartAnts
+	KindExtendedOnly
+)
+
+var kindDescriptions = map[ErrorKind]string{
+	KindFile:                 "cannot read input",
+	KindAntCount:             "invalid number of ants",
+	KindRoomFormat:           "invalid room definition",
+	KindRoomName:             "invalid room name",
+	KindCoordinates:          "invalid coordinates",
+	KindDuplicateRoom:        "duplicate room",
+	KindDuplicateCoordinates: "duplicate coordinates",
+	KindMultipleStart:        "multiple start rooms",
+	KindMultipleEnd:          "multiple end rooms",
+	KindLinkFormat:           "invalid link",
+	KindMissingStart:         "no start room",
+	KindMissingEnd:           "no end room",
+	KindNoLinks:              "no links",
+	KindUnknownRoom:          "link to unknown room",
+	KindSelfLink:             "room linked to itself",
+	KindDuplicateLink:        "duplicate link",
+	KindRoomAfterLinks:       "room declared after links",
+	KindMoveFormat:           "invalid move",
+	KindCapacity:             "invalid room capacity",
+	KindTunnel:               "invalid tunnel length or width",
+	KindStartAnts:            "invalid number of ants for start room",
+	KindExtendedOnly:         "allowed only in extended mode",
+}
+
+func (k ErrorKind) String() string {
+	if d, ok := kindDescriptions[k]; ok {
+		return d
+	}
+	return fmt.Sprintf("ErrorKind(%d)", int(k))
+}
+
+// ParseError reports a problem in the input with its position.
+// Line and Column are 1-based; they are zero for problems with the map as a whole.
+// ParseError сообщает о проблеме во входных данных и ее позиции.
+// Line и Column начинаются с 1; для проблем всей карты они равны нулю.
+type ParseError struct {
+	Line   int
+	Column int
+	Text   string
+	Kind   ErrorKind
+	Err    error
+}
+
+// Error keeps the "ERROR: invalid data format" prefix required by the output format.
+// Error сохраняет префикс "ERROR: invalid data format", требуемый форматом вывода.
+func (e *ParseError) Error() string {
+	return "ERROR: invalid data format, " + e.Message()
+}
+
+// Message describes the problem and its position without the error prefix.
+// Message описывает проблему и ее положение без префикса ошибки.
+func (e *ParseError) Message() string {
+	msg := e.Kind.String()
+	if e.Line > 0 {
+		msg += fmt.Sprintf(" (line %d, column %d: %q)", e.Line, e.Column, e.Text)
+	} else if e.Text != "" {
+		msg += fmt.Sprintf(" (%s)", e.Text)
+	}
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// newLineError builds a ParseError pointing at the given field of a line.
+// newLineError создает ParseError, указывающую на заданное поле строки.
+func newLineError(kind ErrorKind, lineNum int, line string, field int) *ParseError {
+	column, text := fieldPosition(line, field)
+	return &ParseError{Line: lineNum, Column: column, Text: text, Kind: kind}
+}
+
+// newLinkError builds a ParseError pointing at one end of the link "A-B" or "A>B"
+// in the first field of a line: end 0 is A, end 1 is B.
+// newLinkError создает ParseError, указывающую на один из концов связи "A-B" или "A>B".
+func newLinkError(kind ErrorKind, lineNum int, line string, end int) *ParseError {
+	column, token := fieldPosition(line, 0)
+	from, to := token, ""
+	if i := strings.IndexAny(token, "->"); i >= 0 {
+		from, to = token[:i], token[i+1:]
+	}
+	if end == 0 {
+		return &ParseError{Line: lineNum, Column: column, Text: from, Kind: kind}
+	}
+	return &ParseError{Line: lineNum, Column: column + len(from) + 1, Text: to, Kind: kind}
+}
+
+// fieldPosition returns the 1-based column and the text of the n-th
+// whitespace-separated field; past the last field it points at the line end.
+// fieldPosition возвращает колонку (с 1) и текст n-го поля строки.
+func fieldPosition(line string, n int) (int, string) {
+	inField := false
+	index := -1
+	start := 0
+	for i, r := range line {
+		isSpace := r == ' ' || r == '\t'
+		if !isSpace && !inField {
+			index++
+			start = i
+		}
+		if isSpace && inField && index == n {
+			return start + 1, line[start:i]
+		}
+		inField = !isSpace
+	}
+	if inField && index == n {
+		return start + 1, line[start:]
+	}
+	return len(line) + 1, line
+}
+
+// ErrorList is every problem found in one input, in input order.
+// ErrorList — все проблемы, найденные в одном входе, в порядке следования.
+type ErrorList []*ParseError
+
+func (l ErrorList) Error() string {
+	msgs := make([]string, len(l))
+	for i, e := range l {
+		msgs[i] = e.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
@@ -0,0 +1,85 @@
+package parser
+
+import (
+	"bufio"
+	"io"
+	"lem-in/internal/models"
+	"strconv"
+	"strings"
+)
+
+// ParseMoves reads a transcript with one line of "Lx-room" moves per turn.
+// Everything before the first move line, such as the map echoed by the solver
+// and the blank line after it, is skipped. Other blank lines are turns without
+// moves, which happen while ants cross long tunnels; trailing ones are ignored.
+// The From field of the moves is left empty: text transcripts do not carry it.
+// ParseMoves читает запись ходов: одна строка ходов "Lx-room" на ход.
+// Все до первой строки ходов, например эхо карты и пустая строка после него,
+// пропускается. Остальные пустые строки — ходы без переходов, которые бывают,
+// пока муравьи идут по длинным туннелям; пустые строки в конце игнорируются.
+// Поле From остается пустым: текстовая запись его не содержит.
+func ParseMoves(r io.Reader) ([][]models.Move, error) {
+	var turns [][]models.Move
+	scanner := bufio.NewScanner(r)
+	lineNum := 0
+	blanks := 0
+	echoed := false
+	for scanner.Scan() {
+		line := scanner.Text()
+		lineNum++
+
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			blanks++
+			continue
+		}
+		if turns == nil && !strings.HasPrefix(trimmed, "L") {
+			echoed, blanks = true, 0
+			continue
+		}
+		// One blank line separates the echoed map from the moves
+		// Одна пустая строка отделяет эхо карты от ходов
+		if turns == nil && echoed && blanks > 0 {
+			blanks--
+		}
+		for ; blanks > 0; blanks-- {
+			turns = append(turns, []models.Move{})
+		}
+
+		moves, err := parseTurn(line, len(turns)+1)
+		if err != nil {
+			err.Line = lineNum
+			return nil, err
+		}
+		turns = append(turns, moves)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, &ParseError{Kind: KindFile, Text: "moves", Err: err}
+	}
+	return turns, nil
+}
+
+// ParseTurn parses one line of "Lx-room" moves made during the given turn.
+// ParseTurn разбирает одну строку ходов "Lx-room", сделанных на заданном ходу.
+func ParseTurn(line string, turn int) ([]models.Move, error) {
+	moves, err := parseTurn(line, turn)
+	if err != nil {
+		return nil, err
+	}
+	return moves, nil
+}
+
+func parseTurn(line string, turn int) ([]models.Move, *ParseError) {
+	fields := strings.Fields(line)
+	moves := make([]models.Move, 0, len(fields))
+	for i, token := range fields {
+		idPart, room, found := strings.Cut(strings.TrimPrefix(token, "L"), "-")
+		id, err := strconv.Atoi(idPart)
+		if !strings.HasPrefix(token, "L") || !found || room == "" || err != nil {
+			column, text := fieldPosition(line, i)
+			return nil, &ParseError{Column: column, Text: text, Kind: KindMoveFormat}
+		}
+		moves = append(moves, models.Move{AntID: id, To: room, Turn: turn})
+	}
+	return moves, nil
+}
@@ -5,149 +5,452 @@ package parser
 import (
 	"bufio"
 	"fmt"
+	"io"
 	"lem-in/internal/models"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
 )
 
+// Parser reads maps with configurable strictness.
+// Parser читает карты с настраиваемой строгостью.
+type Parser struct {
+	// Lenient turns link problems (unknown rooms, self-links, duplicate links,
+	// rooms declared after links) into warnings; the offending links are dropped.
+	// Lenient превращает проблемы связей в предупреждения; такие связи отбрасываются.
+	Lenient bool
+
+	// Extended accepts several ##start and ##end rooms and per-start ant counts ("##start 5").
+	// Extended разрешает несколько комнат ##start и ##end и число муравьев для старта ("##start 5").
+	Extended bool
+
+	// Warnings holds the problems downgraded by the last Parse call.
+	// Warnings содержит проблемы, пониженные до предупреждений последним вызовом Parse.
+	Warnings ErrorList
+}
+
 // Parse reads a file and converts it into a Farm structure.
+// It stops at the first problem and returns it as a *ParseError.
 // Parse читает файл и преобразует его в структуру Farm.
+// Останавливается на первой проблеме и возвращает ее как *ParseError.
 func Parse(filename string) (*models.Farm, error) {
+	return (&Parser{}).Parse(filename)
+}
+
+// ParseReader reads a map from any stream: a pipe, an HTTP body or a string.
+// ParseReader читает карту из любого потока: канала, тела HTTP-запроса или строки.
+func ParseReader(r io.Reader) (*models.Farm, error) {
+	return (&Parser{}).ParseReader(r)
+}
+
+// Parse reads a file with the parser's settings.
+// Parse читает файл с настройками парсера.
+func (p *Parser) Parse(filename string) (*models.Farm, error) {
+	p.Warnings = nil
 	file, err := os.Open(filename)
 	if err != nil {
-		return nil, fmt.Errorf("ERROR: invalid data format")
+		return nil, &ParseError{Kind: KindFile, Text: filename, Err: err}
 	}
 	defer file.Close()
 
-	farm := &models.Farm{
-		Rooms:    make(map[string]*models.Room),
-		RawLines: make([]string, 0),
-		Links:    make([]string, 0),
+	return p.parseReader(file, filename)
+}
+
+// ParseReader reads a map from r with the parser's settings.
+// ParseReader читает карту из r с настройками парсера.
+func (p *Parser) ParseReader(r io.Reader) (*models.Farm, error) {
+	return p.parseReader(r, "input")
+}
+
+func (p *Parser) parseReader(r io.Reader, name string) (*models.Farm, error) {
+	s := newParseState(p.Lenient, p.Extended)
+	farm, errs := s.parse(r, name, false)
+	// Deferred link checks append out of order
+	// Отложенные проверки связей добавляются не по порядку
+	sort.SliceStable(s.warnings, func(i, j int) bool {
+		return s.warnings[i].Line < s.warnings[j].Line
+	})
+	p.Warnings = s.warnings
+	if len(errs) > 0 {
+		return nil, errs[0]
 	}
+	return farm, nil
+}
 
-	scanner := bufio.NewScanner(file)
-	lineNum := 0
-	var isStart, isEnd bool
+// Lint reads a file like Parse but keeps going after errors and returns every
+// problem found, in input order. An empty list means the map is valid.
+// Lint читает файл как Parse, но не останавливается на ошибках и возвращает
+// все найденные проблемы в порядке следования. Пустой список — карта валидна.
+func Lint(filename string) ErrorList {
+	return (&Parser{}).Lint(filename)
+}
 
-	for scanner.Scan() {
-		line := scanner.Text() // Убираем TrimSpace здесь, чтобы сохранить формат для RawLines
-		trimmed := strings.TrimSpace(line)
+// LintReader reports every problem in a map read from r.
+// LintReader сообщает обо всех проблемах карты, прочитанной из r.
+func LintReader(r io.Reader) ErrorList {
+	return (&Parser{}).LintReader(r)
+}
+
+// Lint reports every problem in a file; Lenient is ignored since every problem is reported.
+// Lint сообщает обо всех проблемах файла; Lenient не учитывается, так как сообщается обо всем.
+func (p *Parser) Lint(filename string) ErrorList {
+	file, err := os.Open(filename)
+	if err != nil {
+		return ErrorList{{Kind: KindFile, Text: filename, Err: err}}
+	}
+	defer file.Close()
 
-		farm.RawLines = append(farm.RawLines, line)
+	_, errs := newParseState(false, p.Extended).parse(file, filename, true)
+	return errs
+}
 
-		// Skip comments (except commands) and empty lines
-		if trimmed == "" || (strings.HasPrefix(trimmed, "#") && !strings.HasPrefix(trimmed, "##")) {
-			continue
-		}
+// LintReader reports every problem in a map read from r with the parser's settings.
+// LintReader сообщает обо всех проблемах карты из r с настройками парсера.
+func (p *Parser) LintReader(r io.Reader) ErrorList {
+	_, errs := newParseState(false, p.Extended).parse(r, "input", true)
+	return errs
+}
+
+// parseState holds everything accumulated while reading one input.
+// parseState хранит все, что накоплено при чтении одного входа.
+type parseState struct {
+	farm           *models.Farm
+	antsParsed     bool
+	isStart, isEnd bool
+	capacity       int // pending ##capacity value, 0 when none
+	startAnts      int // pending "##start N" value, 0 when none
+	extended       bool
 
+	lenient  bool
+	warnings ErrorList
+	linked   map[models.Link]bool
+	// Links whose rooms may still be declared later (lenient mode only)
+	// Связи, комнаты которых еще могут быть объявлены ниже (только мягкий режим)
+	pending []pendingLink
+}
+
+type pendingLink struct {
+	lineNum int
+	line    string
+	link    models.Link
+}
+
+func newParseState(lenient, extended bool) *parseState {
+	return &parseState{
+		farm: &models.Farm{
+			Rooms:     make(map[string]*models.Room),
+			RawLines:  make([]string, 0),
+			Links:     make([]models.Link, 0),
+			StartAnts: make(map[string]int),
+		},
+		lenient:  lenient,
+		extended: extended,
+		linked:   make(map[models.Link]bool),
+	}
+}
+
+// linkProblem reports a link problem as an error, or as a warning in lenient mode.
+// linkProblem сообщает о проблеме связи как об ошибке или, в мягком режиме, как о предупреждении.
+func (s *parseState) linkProblem(err *ParseError) *ParseError {
+	if s.lenient {
+		s.warnings = append(s.warnings, err)
+		return nil
+	}
+	return err
+}
+
+// parse reads the input line by line. With collect set it records every
+// problem and continues; otherwise it returns right after the first one.
+// parse читает вход построчно. С флагом collect записывает все проблемы
+// и продолжает; иначе возвращается сразу после первой.
+func (s *parseState) parse(r io.Reader, name string, collect bool) (*models.Farm, ErrorList) {
+	var errs ErrorList
+
+	scanner := bufio.NewScanner(r)
+	lineNum := 0
+	for scanner.Scan() {
+		line := scanner.Text() // Убираем TrimSpace здесь, чтобы сохранить формат для RawLines
 		lineNum++
 
-		// First non-comment line must be the number of ants
-		if farm.Ants == 0 && !isStart && !isEnd {
-			ants, err := strconv.Atoi(trimmed)
-			if err != nil || ants <= 0 {
-				return nil, fmt.Errorf("ERROR: invalid data format, invalid number of ants")
+		s.farm.RawLines = append(s.farm.RawLines, line)
+
+		if err := s.parseLine(lineNum, line); err != nil {
+			errs = append(errs, err)
+			if !collect {
+				return nil, errs
 			}
-			farm.Ants = ants
-			continue
 		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, append(errs, &ParseError{Kind: KindFile, Text: name, Err: err})
+	}
 
-		// Handle commands
-		if trimmed == "##start" {
-			isStart = true
-			continue
-		} else if trimmed == "##end" {
-			isEnd = true
-			continue
-		}
+	s.resolvePending()
+	errs = append(errs, s.validateFarm()...)
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return s.farm, nil
+}
 
-		// Parse Links or Rooms
-		if strings.Contains(trimmed, "-") {
-			if err := parseLink(farm, trimmed); err != nil {
-				return nil, err
-			}
-		} else {
-			if err := parseRoom(farm, trimmed, &isStart, &isEnd); err != nil {
-				return nil, err
-			}
+// parseLine handles a single input line.
+// parseLine обрабатывает одну строку входа.
+func (s *parseState) parseLine(lineNum int, line string) *ParseError {
+	trimmed := strings.TrimSpace(line)
+
+	// Skip comments (except commands) and empty lines
+	if trimmed == "" || (strings.HasPrefix(trimmed, "#") && !strings.HasPrefix(trimmed, "##")) {
+		return nil
+	}
+
+	// First non-comment line must be the number of ants
+	if !s.antsParsed && !s.isStart && !s.isEnd {
+		s.antsParsed = true
+		ants, err := strconv.Atoi(trimmed)
+		if err != nil || ants <= 0 {
+			return newLineError(KindAntCount, lineNum, line, 0)
 		}
+		s.farm.Ants = ants
+		return nil
 	}
 
-	if err := validateFarm(farm); err != nil {
-		return nil, err
+	// Handle commands
+	if trimmed == "##start" {
+		s.isStart = true
+		return nil
+	} else if strings.HasPrefix(trimmed, "##start ") {
+		return s.parseStartAnts(lineNum, line)
+	} else if trimmed == "##end" {
+		s.isEnd = true
+		return nil
+	} else if strings.HasPrefix(trimmed, "##capacity") {
+		return s.parseCapacity(lineNum, line)
 	}
 
-	return farm, nil
+	// Parse Links or Rooms
+	if strings.ContainsAny(strings.Fields(trimmed)[0], "->") {
+		return s.parseLink(lineNum, line)
+	}
+	return s.parseRoom(lineNum, line)
 }
 
 // parseRoom handles the extraction of room names and coordinates.
 // parseRoom обрабатывает извлечение имен комнат и их координат.
-func parseRoom(farm *models.Farm, line string, isStart, isEnd *bool) error {
+func (s *parseState) parseRoom(lineNum int, line string) *ParseError {
+	// A pending ##start/##end applies to this line even if it turns out to be invalid
+	// Ожидающая команда ##start/##end относится к этой строке, даже если она некорректна
+	isStart, isEnd, capacity, startAnts := s.isStart, s.isEnd, s.capacity, s.startAnts
+	s.isStart, s.isEnd, s.capacity, s.startAnts = false, false, 0, 0
+	if capacity == 0 {
+		capacity = 1
+	}
+
 	parts := strings.Fields(line)
 	if len(parts) != 3 {
-		return fmt.Errorf("ERROR: invalid data format")
+		return newLineError(KindRoomFormat, lineNum, line, 0)
 	}
 
 	name := parts[0]
 	if strings.HasPrefix(name, "L") || strings.HasPrefix(name, "#") {
-		return fmt.Errorf("ERROR: invalid data format")
+		return newLineError(KindRoomName, lineNum, line, 0)
+	}
+
+	x, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return newLineError(KindCoordinates, lineNum, line, 1)
+	}
+	y, err := strconv.Atoi(parts[2])
+	if err != nil {
+		return newLineError(KindCoordinates, lineNum, line, 2)
 	}
 
-	x, err1 := strconv.Atoi(parts[1])
-	y, err2 := strconv.Atoi(parts[2])
-	if err1 != nil || err2 != nil {
-		return fmt.Errorf("ERROR: invalid data format")
+	if _, exists := s.farm.Rooms[name]; exists {
+		return newLineError(KindDuplicateRoom, lineNum, line, 0)
 	}
 
-	if _, exists := farm.Rooms[name]; exists {
-		return fmt.Errorf("ERROR: invalid data format, duplicate room")
+	if len(s.farm.Links) > 0 || len(s.pending) > 0 {
+		if err := s.linkProblem(newLineError(KindRoomAfterLinks, lineNum, line, 0)); err != nil {
+			return err
+		}
 	}
 
 	// Coordinate uniqueness check
-	for _, r := range farm.Rooms {
+	for _, r := range s.farm.Rooms {
 		if r.X == x && r.Y == y {
-			return fmt.Errorf("ERROR: invalid data format, duplicate coordinates")
+			return newLineError(KindDuplicateCoordinates, lineNum, line, 1)
 		}
 	}
 
-	farm.Rooms[name] = &models.Room{Name: name, X: x, Y: y}
+	s.farm.Rooms[name] = &models.Room{Name: name, X: x, Y: y, Capacity: capacity}
 
-	if *isStart {
-		if farm.Start != "" {
-			return fmt.Errorf("ERROR: multiple start rooms")
+	if isStart {
+		if s.farm.Start != "" && !s.extended {
+			return newLineError(KindMultipleStart, lineNum, line, 0)
+		}
+		if s.farm.Start == "" {
+			s.farm.Start = name
+		}
+		s.farm.Starts = append(s.farm.Starts, name)
+		if startAnts > 0 {
+			s.farm.StartAnts[name] = startAnts
 		}
-		farm.Start = name
-		*isStart = false
 	}
-	if *isEnd {
-		if farm.End != "" {
-			return fmt.Errorf("ERROR: multiple end rooms")
+	if isEnd {
+		if s.farm.End != "" && !s.extended {
+			return newLineError(KindMultipleEnd, lineNum, line, 0)
 		}
-		farm.End = name
-		*isEnd = false
+		if s.farm.End == "" {
+			s.farm.End = name
+		}
+		s.farm.Ends = append(s.farm.Ends, name)
 	}
 	return nil
 }
 
-// parseLink handles connection strings like "A-B".
-// parseLink обрабатывает строки связей вида "A-B".
-func parseLink(farm *models.Farm, line string) error {
-	parts := strings.Split(line, "-")
-	if len(parts) != 2 {
-		return fmt.Errorf("ERROR: invalid data format")
+// parseStartAnts handles "##start N" (extended mode only): the next room is a
+// start and exactly N ants leave from it.
+// parseStartAnts обрабатывает "##start N" (только в расширенном режиме): следующая
+// комната — старт, и из нее выходят ровно N муравьев.
+func (s *parseState) parseStartAnts(lineNum int, line string) *ParseError {
+	if !s.extended {
+		return newLineError(KindExtendedOnly, lineNum, line, 1)
+	}
+	parts := strings.Fields(line)
+	ants, err := strconv.Atoi(parts[1])
+	if len(parts) != 2 || err != nil || ants <= 0 {
+		return newLineError(KindStartAnts, lineNum, line, 1)
+	}
+	s.isStart, s.startAnts = true, ants
+	return nil
+}
+
+// parseCapacity handles "##capacity N", which lets the next room hold N ants at once.
+// parseCapacity обрабатывает "##capacity N": следующая комната вмещает N муравьев одновременно.
+func (s *parseState) parseCapacity(lineNum int, line string) *ParseError {
+	parts := strings.Fields(line)
+	if len(parts) != 2 || parts[0] != "##capacity" {
+		return newLineError(KindCapacity, lineNum, line, 0)
+	}
+	capacity, err := strconv.Atoi(parts[1])
+	if err != nil || capacity <= 0 {
+		return newLineError(KindCapacity, lineNum, line, 1)
+	}
+	s.capacity = capacity
+	return nil
+}
+
+// parseLink handles connection strings like "A-B" or, for one-way tunnels, "A>B",
+// optionally followed by the tunnel length ("A-B 3") and width ("A-B x2") in any order.
+// parseLink обрабатывает строки связей вида "A-B" или, для односторонних туннелей, "A>B",
+// за которыми могут следовать длина туннеля ("A-B 3") и его ширина ("A-B x2") в любом порядке.
+func (s *parseState) parseLink(lineNum int, line string) *ParseError {
+	fields := strings.Fields(line)
+	parts := strings.FieldsFunc(fields[0], isLinkSeparator)
+	if len(parts) != 2 || strings.Count(fields[0], "-")+strings.Count(fields[0], ">") != 1 {
+		return newLineError(KindLinkFormat, lineNum, line, 0)
+	}
+	link := models.Link{From: parts[0], To: parts[1], Length: 1, Width: 1, Directed: strings.Contains(fields[0], ">")}
+
+	seenLength, seenWidth := false, false
+	for i, field := range fields[1:] {
+		isWidth := strings.HasPrefix(field, "x")
+		value, err := strconv.Atoi(strings.TrimPrefix(field, "x"))
+		if err != nil || value <= 0 || (isWidth && seenWidth) || (!isWidth && seenLength) {
+			return newLineError(KindTunnel, lineNum, line, i+1)
+		}
+		if isWidth {
+			link.Width, seenWidth = value, true
+		} else {
+			link.Length, seenLength = value, true
+		}
+	}
+
+	if link.From == link.To {
+		return s.linkProblem(newLinkError(KindSelfLink, lineNum, line, 1))
+	}
+
+	// In lenient mode the rooms may still be declared below, so the check waits for the end
+	// В мягком режиме комнаты еще могут быть объявлены ниже, поэтому проверка ждет конца файла
+	if s.lenient {
+		s.pending = append(s.pending, pendingLink{lineNum: lineNum, line: line, link: link})
+		return nil
+	}
+	return s.addLink(lineNum, line, link)
+}
+
+// addLink checks that both rooms exist and the tunnel is new, then records it.
+// addLink проверяет, что обе комнаты существуют и туннель новый, и сохраняет его.
+func (s *parseState) addLink(lineNum int, line string, link models.Link) *ParseError {
+	if _, ok := s.farm.Rooms[link.From]; !ok {
+		return s.linkProblem(newLinkError(KindUnknownRoom, lineNum, line, 0))
 	}
-	// Basic validation: do rooms exist?
-	// Note: Detailed validation can be done after parsing all rooms.
-	farm.Links = append(farm.Links, line)
+	if _, ok := s.farm.Rooms[link.To]; !ok {
+		return s.linkProblem(newLinkError(KindUnknownRoom, lineNum, line, 1))
+	}
+
+	// linked records every direction already served by a tunnel, whatever its
+	// length or width: a one-way tunnel may only be paired with the opposite one
+	// linked хранит каждое направление, которое уже обслуживает туннель, независимо
+	// от его длины и ширины: односторонний туннель можно дополнить только встречным
+	key := models.Link{From: link.From, To: link.To}
+	reverse := models.Link{From: link.To, To: link.From}
+	if s.linked[key] || (!link.Directed && s.linked[reverse]) {
+		return s.linkProblem(newLineError(KindDuplicateLink, lineNum, line, 0))
+	}
+	s.linked[key] = true
+	if !link.Directed {
+		s.linked[reverse] = true
+	}
+
+	s.farm.Links = append(s.farm.Links, link)
 	return nil
 }
 
+// isLinkSeparator reports whether r separates the rooms of a link.
+// isLinkSeparator сообщает, разделяет ли r комнаты связи.
+func isLinkSeparator(r rune) bool {
+	return r == '-' || r == '>'
+}
+
+// resolvePending validates the links deferred in lenient mode once every room is known.
+// resolvePending проверяет отложенные в мягком режиме связи, когда известны все комнаты.
+func (s *parseState) resolvePending() {
+	for _, p := range s.pending {
+		s.addLink(p.lineNum, p.line, p.link)
+	}
+	s.pending = nil
+}
+
 // validateFarm ensures the minimum requirements for a valid colony.
 // validateFarm проверяет минимальные требования для валидной колонии.
-func validateFarm(farm *models.Farm) error {
-	if farm.Ants <= 0 || farm.Start == "" || farm.End == "" || len(farm.Links) == 0 {
-		return fmt.Errorf("ERROR: invalid data format")
+func (s *parseState) validateFarm() ErrorList {
+	var errs ErrorList
+	if !s.antsParsed {
+		errs = append(errs, &ParseError{Kind: KindAntCount})
 	}
-	return nil
+	if s.farm.Start == "" {
+		errs = append(errs, &ParseError{Kind: KindMissingStart})
+	}
+	if s.farm.End == "" {
+		errs = append(errs, &ParseError{Kind: KindMissingEnd})
+	}
+	if len(s.farm.Links) == 0 {
+		errs = append(errs, &ParseError{Kind: KindNoLinks})
+	}
+
+	// Fixed per-start counts may not exceed the ants, and must add up to them
+	// when every start has one
+	// Заданные для стартов числа не могут превышать число муравьев и должны
+	// в сумме давать его, если число задано для каждого старта
+	fixed := 0
+	for _, ants := range s.farm.StartAnts {
+		fixed += ants
+	}
+	if s.antsParsed && len(s.farm.StartAnts) > 0 {
+		if fixed > s.farm.Ants || (len(s.farm.StartAnts) == len(s.farm.Starts) && fixed != s.farm.Ants) {
+			errs = append(errs, &ParseError{Kind: KindStartAnts, Text: fmt.Sprintf("%d ants assigned to starts, %d in total", fixed, s.farm.Ants)})
+		}
+	}
+	return errs
 }
@@ -0,0 +1,150 @@
+package parser_test
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"lem-in/internal/models"
+	"lem-in/internal/parser"
+)
+
+// farm joins lines into a map text.
+func farm(lines ...string) string {
+	return strings.Join(lines, "\n")
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		extended bool
+		input    string
+		kind     parser.ErrorKind
+		line     int
+		column   int
+	}{
+		{"bad ant count", false, farm("x", "##start", "a 0 0"), parser.KindAntCount, 1, 1},
+		{"no ants", false, farm("0", "##start", "a 0 0"), parser.KindAntCount, 1, 1},
+		{"duplicate room", false, farm("1", "##start", "a 0 0", "a 1 1"), parser.KindDuplicateRoom, 4, 1},
+		{"duplicate coordinates", false, farm("1", "##start", "a 0 0", "b 0 0"), parser.KindDuplicateCoordinates, 4, 3},
+		{"bad coordinate", false, farm("1", "a 0 y"), parser.KindCoordinates, 2, 5},
+		{"room named L", false, farm("1", "La 0 0"), parser.KindRoomName, 2, 1},
+		{"unknown room in link", false, farm("1", "##start", "a 0 0", "##end", "b 1 0", "a-c"), parser.KindUnknownRoom, 6, 3},
+		{"self link", false, farm("1", "##start", "a 0 0", "##end", "b 1 0", "a-a"), parser.KindSelfLink, 6, 3},
+		{"duplicate link", false, farm("1", "##start", "a 0 0", "##end", "b 1 0", "a-b", "b-a"), parser.KindDuplicateLink, 7, 1},
+		{"room after links", false, farm("1", "##start", "a 0 0", "##end", "b 1 0", "a-b", "c 2 0"), parser.KindRoomAfterLinks, 7, 1},
+		{"missing start", false, farm("1", "a 0 0", "##end", "b 1 0", "a-b"), parser.KindMissingStart, 0, 0},
+		{"missing end", false, farm("1", "##start", "a 0 0", "b 1 0", "a-b"), parser.KindMissingEnd, 0, 0},
+		{"no links", false, farm("1", "##start", "a 0 0", "##end", "b 1 0"), parser.KindNoLinks, 0, 0},
+		{"bad capacity", false, farm("1", "##capacity 0", "a 0 0"), parser.KindCapacity, 2, 12},
+		{"bad tunnel length", false, farm("1", "##start", "a 0 0", "##end", "b 1 0", "a-b 0"), parser.KindTunnel, 6, 5},
+		{"repeated tunnel width", false, farm("1", "##start", "a 0 0", "##end", "b 1 0", "a-b x2 x3"), parser.KindTunnel, 6, 8},
+		{"two separators", false, farm("1", "##start", "a 0 0", "##end", "b 1 0", "a>b-c"), parser.KindLinkFormat, 6, 1},
+		{"one-way duplicate", false, farm("1", "##start", "a 0 0", "##end", "b 1 0", "a>b", "a-b"), parser.KindDuplicateLink, 7, 1},
+		{"second start", false, farm("1", "##start", "a 0 0", "##start", "b 1 0"), parser.KindMultipleStart, 5, 1},
+		{"second end", false, farm("1", "##end", "a 0 0", "##end", "b 1 0"), parser.KindMultipleEnd, 5, 1},
+		{"start ants in plain mode", false, farm("1", "##start 1", "a 0 0"), parser.KindExtendedOnly, 2, 9},
+		{"bad start ants", true, farm("1", "##start x", "a 0 0"), parser.KindStartAnts, 2, 9},
+		{"too many start ants", true, farm("2", "##start 3", "a 0 0", "##end", "b 1 0", "a-b"), parser.KindStartAnts, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := (&parser.Parser{Extended: tt.extended}).ParseReader(strings.NewReader(tt.input))
+			var perr *parser.ParseError
+			if !errors.As(err, &perr) {
+				t.Fatalf("got %v, want a *ParseError", err)
+			}
+			if perr.Kind != tt.kind || perr.Line != tt.line || perr.Column != tt.column {
+				t.Errorf("got %v at %d:%d, want %v at %d:%d", perr.Kind, perr.Line, perr.Column, tt.kind, tt.line, tt.column)
+			}
+			if !strings.HasPrefix(err.Error(), "ERROR: invalid data format") {
+				t.Errorf("error %q lacks the required prefix", err)
+			}
+		})
+	}
+}
+
+func TestParseExtendedSyntax(t *testing.T) {
+	input := farm(
+		"5",
+		"##start 2",
+		"s0 0 0",
+		"##start",
+		"s1 0 1",
+		"##capacity 3",
+		"r 1 0",
+		"##end",
+		"e0 2 0",
+		"##end",
+		"e1 2 1",
+		"s0-r 3",
+		"s1-r x2 4",
+		"r>e0",
+		"e1-r",
+	)
+	got, err := (&parser.Parser{Extended: true}).ParseReader(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !slices.Equal(got.Starts, []string{"s0", "s1"}) || !slices.Equal(got.Ends, []string{"e0", "e1"}) {
+		t.Errorf("starts %v, ends %v", got.Starts, got.Ends)
+	}
+	if got.Start != "s0" || got.End != "e0" {
+		t.Errorf("start %q, end %q; want the first of each", got.Start, got.End)
+	}
+	if got.StartAnts["s0"] != 2 || len(got.StartAnts) != 1 {
+		t.Errorf("start ants %v, want only s0: 2", got.StartAnts)
+	}
+	if got.Rooms["r"].Capacity != 3 || got.Rooms["s1"].Capacity != 1 {
+		t.Errorf("capacities %d and %d, want 3 and 1", got.Rooms["r"].Capacity, got.Rooms["s1"].Capacity)
+	}
+	want := []models.Link{
+		{From: "s0", To: "r", Length: 3, Width: 1},
+		{From: "s1", To: "r", Length: 4, Width: 2},
+		{From: "r", To: "e0", Length: 1, Width: 1, Directed: true},
+		{From: "e1", To: "r", Length: 1, Width: 1},
+	}
+	if !slices.Equal(got.Links, want) {
+		t.Errorf("links\n%v\nwant\n%v", got.Links, want)
+	}
+}
+
+func TestLenientDropsBadLinks(t *testing.T) {
+	input := farm("1", "a-b", "##start", "a 0 0", "##end", "b 1 0", "a-a", "a-c", "b-a")
+	p := &parser.Parser{Lenient: true}
+	got, err := p.ParseReader(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Links) != 1 {
+		t.Errorf("links %v, want only a-b", got.Links)
+	}
+
+	var kinds []parser.ErrorKind
+	for _, w := range p.Warnings {
+		kinds = append(kinds, w.Kind)
+	}
+	want := []parser.ErrorKind{parser.KindRoomAfterLinks, parser.KindRoomAfterLinks, parser.KindSelfLink, parser.KindUnknownRoom, parser.KindDuplicateLink}
+	if !slices.Equal(kinds, want) {
+		t.Errorf("warnings %v, want %v", kinds, want)
+	}
+}
+
+func TestLintReportsEveryProblem(t *testing.T) {
+	input := farm("1", "##start", "a 0 0", "a 1 1", "b x 0", "##start", "c 2 2", "a-d")
+	var kinds []parser.ErrorKind
+	for _, e := range parser.LintReader(strings.NewReader(input)) {
+		kinds = append(kinds, e.Kind)
+	}
+	want := []parser.ErrorKind{parser.KindDuplicateRoom, parser.KindCoordinates, parser.KindMultipleStart, parser.KindUnknownRoom, parser.KindMissingEnd, parser.KindNoLinks}
+	if !slices.Equal(kinds, want) {
+		t.Errorf("got %v, want %v", kinds, want)
+	}
+
+	if errs := parser.Lint("../../example00.txt"); len(errs) != 0 {
+		t.Errorf("example00.txt: %v", errs)
+	}
+}
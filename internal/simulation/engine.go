@@ -3,56 +3,97 @@
 package simulation
 
 import (
-	"fmt"
+	"iter"
+	"lem-in/internal/graph"
 	"lem-in/internal/models"
 	"sort"
-	"strings"
 )
 
-// Run executes the movement simulation step-by-step until all ants reach the end.
-// Run пошагово выполняет симуляцию движения, пока все муравьи не достигнут финиша.
-func Run(paths []models.Path, distribution [][]int) []string {
-	var moves []string
+// TurnEvent holds the moves made during one turn, sorted by ant ID.
+// TurnEvent содержит ходы, сделанные за один ход, отсортированные по ID муравья.
+type TurnEvent struct {
+	Turn  int
+	Moves []models.Move
+}
 
-	// Initialize ant objects based on the distribution layers
-	// Инициализируем объекты муравьев на основе слоев распределения
-	ants := initializeAnts(paths, distribution)
+// Run executes the movement simulation until all ants reach the end.
+// It returns the moves of every turn, sorted by ant ID within a turn.
+// Run выполняет симуляцию движения, пока все муравьи не достигнут финиша.
+// Возвращает ходы каждого хода, отсортированные по ID муравья внутри хода.
+func Run(g *graph.Graph, paths []models.Path, distribution [][]int) [][]models.Move {
+	var turns [][]models.Move
+	for event := range Turns(g, paths, distribution) {
+		turns = append(turns, event.Moves)
+	}
+	return turns
+}
 
-	for {
-		turnMoves := make([]string, 0)
-		occupied := make(map[string]bool)
-		usedTunnels := make(map[string]bool)
-		anyMoved := false
+// Turns yields every turn of the simulation as soon as no later ant can change it,
+// so callers can stream huge simulations without buffering them.
+// Room capacities and tunnel lengths and widths are taken from g. Ants are planned
+// one by one in the order they leave the start: each books the rooms and tunnels
+// it uses for every turn, so it never enters a room that may still be full when it
+// arrives. A move through a long tunnel is reported on the turn the ant arrives,
+// so a turn may have no moves.
+// Turns выдает каждый ход симуляции, как только его не может изменить ни один
+// следующий муравей, чтобы большие симуляции можно было передавать потоком без буферизации.
+// Вместимость комнат, длина и ширина туннелей берутся из g. Муравьи планируются
+// по одному в порядке выхода со старта: каждый бронирует комнаты и туннели на каждый
+// ход, поэтому никогда не входит в комнату, которая может быть заполнена к его прибытию.
+// Переход по длинному туннелю выводится на ходу прибытия муравья, поэтому ход
+// может быть без переходов.
+func Turns(g *graph.Graph, paths []models.Path, distribution [][]int) iter.Seq[TurnEvent] {
+	return func(yield func(TurnEvent) bool) {
+		// Initialize ant objects based on the distribution layers
+		// Инициализируем объекты муравьев на основе слоев распределения
+		ants := initializeAnts(paths, distribution)
+		s := newSchedule(g)
 
-		// Sort ants: those closer to the end move first to free up rooms
-		// Сортировка: те, кто ближе к концу, ходят первыми, освобождая комнаты
-		sort.SliceStable(ants, func(i, j int) bool {
-			return ants[i].Position > ants[j].Position
-		})
+		// Ants still to plan on each path and the earliest turn the next of them may leave
+		// Муравьи, которых еще нужно спланировать на каждом пути, и самый ранний ход выхода следующего из них
+		remaining := make([]int, len(paths))
+		for _, ant := range ants {
+			remaining[ant.PathIndex]++
+		}
+		earliest := make([]int, len(paths))
+		for i := range earliest {
+			earliest[i] = 1
+		}
 
 		for _, ant := range ants {
-			if ant.Finished {
-				continue
-			}
+			earliest[ant.PathIndex] = s.plan(ant, earliest[ant.PathIndex])
+			remaining[ant.PathIndex]--
 
-			// Try to move to the next room in the ant's assigned path
-			// Попытка перейти в следующую комнату согласно назначенному пути
-			if moveAnt(ant, &turnMoves, occupied, usedTunnels) {
-				anyMoved = true
+			// Ants planned later leave no earlier than their paths allow, so the turns
+			// before that are final
+			// Следующие муравьи выходят не раньше, чем позволяют их пути, поэтому
+			// ходы до этого момента окончательны
+			settled := s.last + 1
+			for i, count := range remaining {
+				if count > 0 {
+					settled = min(settled, earliest[i])
+				}
+			}
+			if !s.flush(settled, yield) {
+				return
 			}
 		}
+		s.flush(s.last+1, yield)
+	}
+}
 
-		if !anyMoved {
-			break
+// Replay yields the turns of a schedule planned in advance, such as one made by
+// a solver that moves the ants itself.
+// Replay выдает ходы заранее составленного расписания, например созданного
+// стратегией, которая сама передвигает муравьев.
+func Replay(turns [][]models.Move) iter.Seq[TurnEvent] {
+	return func(yield func(TurnEvent) bool) {
+		for i, moves := range turns {
+			if !yield(TurnEvent{Turn: i + 1, Moves: moves}) {
+				return
+			}
 		}
-
-		// Sort output moves by Ant ID for consistent formatting
-		// Сортируем ходы по ID муравья для единообразия вывода
-		sortMoves(turnMoves)
-		moves = append(moves, strings.Join(turnMoves, " "))
 	}
-
-	return moves
 }
 
 // initializeAnts creates an ordered slice of ants to ensure fair start line exit.
@@ -83,40 +124,150 @@ func initializeAnts(paths []models.Path, distribution [][]int) []*models.Ant {
 	return ants
 }
 
-// moveAnt attempts to advance a single ant to its next room.
-func moveAnt(ant *models.Ant, turnMoves *[]string, occupied, usedTunnels map[string]bool) bool {
-	currentRoom := ant.Path[ant.Position]
-	nextRoom := ant.Path[ant.Position+1]
+// schedule holds the bookings of the ants planned so far and their moves not yet yielded.
+// schedule хранит брони уже спланированных муравьев и их еще не выданные ходы.
+type schedule struct {
+	g *graph.Graph
+	// Ants inside each intermediate room at the end of each turn
+	// Муравьи в каждой промежуточной комнате в конце каждого хода
+	occupied map[int]map[string]int
+	// Ants entering each tunnel during each turn
+	// Муравьи, входящие в каждый туннель на каждом ходу
+	entered map[int]map[string]int
+	moves   map[int][]models.Move
+	next    int // first turn not yet yielded
+	last    int // last turn with a move
+}
 
-	tunnelKey := generateTunnelKey(currentRoom, nextRoom)
+func newSchedule(g *graph.Graph) *schedule {
+	return &schedule{
+		g:        g,
+		occupied: make(map[int]map[string]int),
+		entered:  make(map[int]map[string]int),
+		moves:    make(map[int][]models.Move),
+		next:     1,
+	}
+}
+
+// step is an ant standing at a room of its path at the end of a turn.
+type step struct {
+	pos, turn int
+}
 
-	if !usedTunnels[tunnelKey] && (nextRoom == ant.EndRoom || !occupied[nextRoom]) {
-		ant.Position++
-		*turnMoves = append(*turnMoves, fmt.Sprintf("L%d-%s", ant.ID, nextRoom))
-		usedTunnels[tunnelKey] = true
+// plan finds the earliest arrival of the ant at the end of its path, leaving the
+// start no earlier than the given turn, books its rooms and tunnels and records
+// its moves. It returns the turn the ant leaves the start.
+// An ant may wait in a room only while the room has a free place, and enters a
+// room only when a place is free on the turn it arrives; the ants planned before
+// never give up their bookings, so an ant can always wait at the start until the
+// path is clear.
+// plan находит самое раннее прибытие муравья в конец пути с выходом со старта не
+// раньше заданного хода, бронирует комнаты и туннели и записывает ходы.
+// Возвращает ход выхода муравья со старта. Муравей ждет в комнате, только пока
+// в ней есть место, и входит в комнату, только если место свободно на ходу прибытия;
+// ранее спланированные муравьи не отказываются от брони, поэтому на старте
+// всегда можно дождаться, пока путь освободится.
+func (s *schedule) plan(ant *models.Ant, earliest int) int {
+	rooms := ant.Path
+	first := step{pos: 0, turn: earliest - 1}
+	previous := map[step]step{first: first}
+	// Positions reached by the end of each turn, searched in the order of turns
+	// Позиции, достигнутые к концу каждого хода; просматриваются в порядке ходов
+	reached := map[int][]int{first.turn: {0}}
 
-		if nextRoom == ant.EndRoom {
-			ant.Finished = true
+	for turn := first.turn; ; turn++ {
+		for _, pos := range reached[turn] {
+			at := step{pos: pos, turn: turn}
+			if pos == len(rooms)-1 {
+				return s.book(ant, previous, at)
+			}
+
+			// Wait where the ant is, or leave through the next tunnel
+			// Ждем на месте или уходим по следующему туннелю
+			tunnel := s.g.Tunnel(rooms[pos], rooms[pos+1])
+			next := []step{}
+			if s.hasPlace(rooms[pos], turn+1) {
+				next = append(next, step{pos: pos, turn: turn + 1})
+			}
+			if s.entered[turn+1][tunnel.Key] < tunnel.Width && s.hasPlace(rooms[pos+1], turn+tunnel.Length) {
+				next = append(next, step{pos: pos + 1, turn: turn + tunnel.Length})
+			}
+			for _, n := range next {
+				if _, seen := previous[n]; !seen {
+					previous[n] = at
+					reached[n.turn] = append(reached[n.turn], n.pos)
+				}
+			}
+		}
+		delete(reached, turn)
+	}
+}
+
+// book walks the found steps back from the end and makes the bookings.
+// book проходит найденные шаги назад от финиша и оформляет брони.
+func (s *schedule) book(ant *models.Ant, previous map[step]step, at step) int {
+	rooms := ant.Path
+	for at.pos > 0 {
+		from := previous[at]
+		room := rooms[at.pos]
+		if at.pos == from.pos {
+			s.reserve(s.occupied, at.turn, room)
 		} else {
-			occupied[nextRoom] = true
+			s.reserve(s.entered, from.turn+1, s.g.Tunnel(rooms[from.pos], room).Key)
+			s.reserve(s.occupied, at.turn, room)
+			s.moves[at.turn] = append(s.moves[at.turn], models.Move{AntID: ant.ID, From: rooms[from.pos], To: room, Turn: at.turn})
+			s.last = max(s.last, at.turn)
 		}
-		return true
+		at = from
 	}
-	return false
+	// The ant leaves the start on the turn after its last step there
+	// Муравей покидает старт на ходу, следующем за его последним шагом там
+	return at.turn + 1
 }
 
-func generateTunnelKey(r1, r2 string) string {
-	if r1 > r2 {
-		return r2 + "-" + r1
+// reserve counts one more ant in a room or tunnel on a turn.
+// reserve учитывает еще одного муравья в комнате или туннеле на ходу.
+func (s *schedule) reserve(bookings map[int]map[string]int, turn int, key string) {
+	if bookings[turn] == nil {
+		bookings[turn] = make(map[string]int)
+	}
+	bookings[turn][key]++
+}
+
+// hasPlace reports whether one more ant fits into the room at the end of the turn.
+// Starts and ends are unlimited.
+// hasPlace сообщает, поместится ли еще один муравей в комнату в конце хода.
+// Старты и финиши не ограничены.
+func (s *schedule) hasPlace(room string, turn int) bool {
+	return s.g.IsStart(room) || s.g.IsEnd(room) || s.occupied[turn][room] < s.g.Capacities[room]
+}
+
+// flush yields the turns before the given one that have not been yielded yet,
+// dropping their bookings. It reports false when the caller stopped the iteration.
+// flush выдает еще не выданные ходы до заданного, удаляя их брони.
+// Возвращает false, если вызывающий прервал перебор.
+func (s *schedule) flush(until int, yield func(TurnEvent) bool) bool {
+	for ; s.next < until; s.next++ {
+		moves := s.moves[s.next]
+		if moves == nil {
+			moves = make([]models.Move, 0)
+		}
+		delete(s.moves, s.next)
+		delete(s.occupied, s.next)
+		delete(s.entered, s.next)
+
+		// Sort output moves by Ant ID for consistent formatting
+		// Сортируем ходы по ID муравья для единообразия вывода
+		sortMoves(moves)
+		if !yield(TurnEvent{Turn: s.next, Moves: moves}) {
+			return false
+		}
 	}
-	return r1 + "-" + r2
+	return true
 }
 
-func sortMoves(moves []string) {
+func sortMoves(moves []models.Move) {
 	sort.Slice(moves, func(i, j int) bool {
-		var id1, id2 int
-		fmt.Sscanf(moves[i], "L%d", &id1)
-		fmt.Sscanf(moves[j], "L%d", &id2)
-		return id1 < id2
+		return moves[i].AntID < moves[j].AntID
 	})
 }
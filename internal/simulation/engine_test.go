@@ -0,0 +1,100 @@
+package simulation_test
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"testing"
+	"time"
+
+	"lem-in/internal/graph"
+	"lem-in/internal/parser"
+	"lem-in/internal/simulation"
+	"lem-in/internal/solver"
+)
+
+// A room of capacity 2 fed by a wide tunnel and a long one, but left through a
+// narrow one: the ants that cannot leave must be counted when booking the room.
+const narrowExit = `5
+##start
+s 0 0
+##capacity 2
+r0 1 0
+r1 2 0
+r2 3 0
+##end
+e 4 0
+s-r2 3
+r2-r0 x2
+r0-e
+r0-s 3 x3`
+
+func TestEverySolverPassesVerify(t *testing.T) {
+	names, maps := []string{"narrow exit"}, []string{narrowExit}
+	rng := rand.New(rand.NewPCG(14, 14))
+	for i := range 300 {
+		names, maps = append(names, fmt.Sprintf("random %d", i)), append(maps, randomMap(rng))
+	}
+
+	for i, text := range maps {
+		name := names[i]
+		farm, err := (&parser.Parser{Lenient: true}).ParseReader(strings.NewReader(text))
+		if err != nil {
+			t.Fatalf("%s: %v\n%s", name, err, text)
+		}
+		for _, solverName := range solver.Names() {
+			s, _ := solver.Get(solverName)
+			g := graph.Build(farm)
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			sol, err := s.Solve(ctx, g, farm.Ants)
+			cancel()
+			if err != nil {
+				continue
+			}
+
+			turns := sol.Schedule
+			if turns == nil {
+				turns = simulation.Run(g, sol.Paths, sol.Distribution)
+			}
+			if _, err := simulation.Verify(farm, turns); err != nil {
+				t.Errorf("%s, %s: %v\n%s", name, solverName, err, text)
+			}
+		}
+	}
+}
+
+// randomMap builds a small map with random capacities, tunnel lengths and widths
+// and one-way tunnels; some of the maps have no path.
+func randomMap(rng *rand.Rand) string {
+	rooms := 3 + rng.IntN(5)
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d\n", 1+rng.IntN(8))
+	for i := range rooms {
+		switch {
+		case i == 0:
+			b.WriteString("##start\n")
+		case i == rooms-1:
+			b.WriteString("##end\n")
+		case rng.IntN(3) == 0:
+			fmt.Fprintf(&b, "##capacity %d\n", 2+rng.IntN(2))
+		}
+		fmt.Fprintf(&b, "r%d %d 0\n", i, i)
+	}
+	for range rooms + rng.IntN(rooms) {
+		from, to := rng.IntN(rooms), rng.IntN(rooms)
+		separator := "-"
+		if rng.IntN(5) == 0 {
+			separator = ">"
+		}
+		fmt.Fprintf(&b, "r%d%sr%d", from, separator, to)
+		if rng.IntN(2) == 0 {
+			fmt.Fprintf(&b, " %d", 1+rng.IntN(3))
+		}
+		if rng.IntN(3) == 0 {
+			fmt.Fprintf(&b, " x%d", 2+rng.IntN(2))
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,196 @@
+package simulation
+
+import (
+	"fmt"
+	"lem-in/internal/graph"
+	"lem-in/internal/models"
+	"slices"
+)
+
+// Violation describes the first rule broken by a move transcript.
+// Violation описывает первое нарушенное правило в записи ходов.
+type Violation struct {
+	Turn   int
+	AntID  int
+	Reason string
+}
+
+func (v *Violation) Error() string {
+	if v.AntID == 0 {
+		return fmt.Sprintf("turn %d: %s", v.Turn, v.Reason)
+	}
+	return fmt.Sprintf("turn %d, ant %d: %s", v.Turn, v.AntID, v.Reason)
+}
+
+// Verify replays a transcript (the moves of every turn) against the farm and checks
+// every movement rule. Moves with an empty From are taken from the ant's current room.
+// A move through a tunnel of length L listed on turn T leaves its room on turn T-L+1.
+// It returns the number of turns in the transcript and a *Violation for the first broken rule.
+// Verify воспроизводит запись ходов на ферме и проверяет все правила передвижения.
+// Ходы с пустым From считаются сделанными из текущей комнаты муравья.
+// Переход по туннелю длины L, записанный на ходу T, покидает комнату на ходу T-L+1.
+// Возвращает число ходов в записи и *Violation для первого нарушенного правила.
+func Verify(farm *models.Farm, turns [][]models.Move) (int, error) {
+	g := graph.Build(farm)
+
+	// First pass: follow every ant along its own moves and place each move on
+	// the timeline by its departure and arrival turns
+	// Первый проход: ведем каждого муравья по его ходам и размещаем каждый
+	// переход на шкале времени по ходам отправления и прибытия
+	departures, arrivals, violation := replayAnts(farm, g, turns)
+	last := len(turns)
+	if violation != nil {
+		last = violation.Turn
+	}
+
+	// Second pass: check tunnels and rooms turn by turn
+	// Второй проход: проверяем туннели и комнаты ход за ходом
+	occupants := make(map[string]int)
+	for turn := 1; turn <= last; turn++ {
+		usedTunnels := make(map[string]int)
+		for _, m := range departures[turn] {
+			tunnel := g.Tunnel(m.From, m.To)
+			usedTunnels[tunnel.Key]++
+			if usedTunnels[tunnel.Key] > tunnel.Width {
+				return len(turns), &Violation{Turn: m.Turn, AntID: m.AntID, Reason: fmt.Sprintf("tunnel %s is already used this turn", tunnel.Key)}
+			}
+		}
+
+		// Rooms are freed before they are filled: an ant may enter a room
+		// that another ant leaves during the same turn
+		// Комнаты освобождаются раньше, чем заполняются: муравей может войти
+		// в комнату, которую другой муравей покидает на этом же ходу
+		for _, m := range departures[turn] {
+			occupants[m.From]--
+		}
+		for _, m := range arrivals[turn] {
+			occupants[m.To]++
+			if !g.IsStart(m.To) && !g.IsEnd(m.To) && occupants[m.To] > g.Capacities[m.To] {
+				return len(turns), &Violation{Turn: m.Turn, AntID: m.AntID, Reason: fmt.Sprintf("room %s is already occupied", m.To)}
+			}
+		}
+	}
+	if violation != nil {
+		return len(turns), violation
+	}
+	return len(turns), nil
+}
+
+// replayAnts checks the rules that concern each ant on its own and groups the
+// moves by the turn they leave and enter a room. It stops at the first violation.
+// replayAnts проверяет правила, касающиеся каждого муравья в отдельности, и
+// группирует переходы по ходам выхода из комнаты и входа в нее.
+func replayAnts(farm *models.Farm, g *graph.Graph, turns [][]models.Move) (map[int][]models.Move, [][]models.Move, *Violation) {
+	// Every ant begins in a start room of its group; when the group has several
+	// starts, the first move tells which one
+	// Каждый муравей начинает в стартовой комнате своей группы; если стартов
+	// в группе несколько, какой из них — становится ясно по первому ходу
+	position := make([]string, farm.Ants+1)
+	arrived := make([]int, farm.Ants+1)
+	starts := make([][]string, farm.Ants+1)
+	for _, group := range g.AntGroups(farm.Ants) {
+		for id := group.First; id < group.First+group.Count; id++ {
+			position[id], starts[id] = group.Starts[0], group.Starts
+		}
+	}
+	departures := make(map[int][]models.Move)
+	arrivals := make([][]models.Move, len(turns)+1)
+
+	for i, moves := range turns {
+		turn := i + 1
+		movedAnts := make(map[int]bool)
+
+		for _, m := range moves {
+			id, room := m.AntID, m.To
+			if id < 1 || id > farm.Ants {
+				return departures, arrivals, &Violation{Turn: turn, AntID: id, Reason: "no such ant"}
+			}
+			if !g.Rooms[room] {
+				return departures, arrivals, &Violation{Turn: turn, AntID: id, Reason: fmt.Sprintf("unknown room %q", room)}
+			}
+			if movedAnts[id] {
+				return departures, arrivals, &Violation{Turn: turn, AntID: id, Reason: "ant moves twice in one turn"}
+			}
+			from := position[id]
+			if arrived[id] == 0 && len(starts[id]) > 1 {
+				from = pickStart(g, starts[id], m, turn, departures)
+			}
+			if m.From != "" && m.From != from {
+				return departures, arrivals, &Violation{Turn: turn, AntID: id, Reason: fmt.Sprintf("ant is in %s, not in %s", from, m.From)}
+			}
+			if g.IsEnd(from) {
+				return departures, arrivals, &Violation{Turn: turn, AntID: id, Reason: "ant has already reached the end"}
+			}
+			if !isLinked(g, from, room) && isLinked(g, room, from) {
+				return departures, arrivals, &Violation{Turn: turn, AntID: id, Reason: fmt.Sprintf("tunnel %s only leads from %s to %s", graph.OneWayKey(room, from), room, from)}
+			}
+			if !isLinked(g, from, room) {
+				return departures, arrivals, &Violation{Turn: turn, AntID: id, Reason: fmt.Sprintf("no tunnel between %s and %s", from, room)}
+			}
+			depart := turn - g.Tunnel(from, room).Length + 1
+			if depart <= arrived[id] {
+				return departures, arrivals, &Violation{Turn: turn, AntID: id, Reason: fmt.Sprintf("ant cannot leave %s on turn %d", from, depart)}
+			}
+
+			movedAnts[id] = true
+			move := models.Move{AntID: id, From: from, To: room, Turn: turn}
+			departures[depart] = append(departures[depart], move)
+			arrivals[turn] = append(arrivals[turn], move)
+			position[id], arrived[id] = room, turn
+		}
+	}
+
+	for id := 1; id <= farm.Ants; id++ {
+		if !g.IsEnd(position[id]) {
+			return departures, arrivals, &Violation{Turn: len(turns), AntID: id, Reason: fmt.Sprintf("ant never reaches the end, stuck in %s", position[id])}
+		}
+	}
+	return departures, arrivals, nil
+}
+
+// pickStart chooses the start room an ant leaves with its first move: the
+// one named in the move, or else the first start linked to the destination
+// whose tunnel still has room on the turn the ant would leave. When no start
+// fits, the first linked one is taken so that the violation is reported.
+// pickStart выбирает старт, из которого муравей выходит первым ходом: указанный
+// в ходе или первый старт, связанный с комнатой назначения, в туннеле которого
+// еще есть место на ходу выхода. Если подходящего старта нет, берется первый
+// связанный, чтобы сообщить о нарушении.
+func pickStart(g *graph.Graph, starts []string, m models.Move, turn int, departures map[int][]models.Move) string {
+	if m.From != "" && slices.Contains(starts, m.From) {
+		return m.From
+	}
+	linked := ""
+	for _, s := range starts {
+		if !isLinked(g, s, m.To) {
+			continue
+		}
+		if linked == "" {
+			linked = s
+		}
+		tunnel := g.Tunnel(s, m.To)
+		depart := turn - tunnel.Length + 1
+		used := 0
+		for _, d := range departures[depart] {
+			if g.Tunnel(d.From, d.To).Key == tunnel.Key {
+				used++
+			}
+		}
+		if depart >= 1 && used < tunnel.Width {
+			return s
+		}
+	}
+	if linked != "" {
+		return linked
+	}
+	return starts[0]
+}
+
+func isLinked(g *graph.Graph, from, to string) bool {
+	for _, next := range g.AdjacencyList[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,103 @@
+package simulation_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"lem-in/internal/models"
+	"lem-in/internal/parser"
+	"lem-in/internal/simulation"
+)
+
+// Ants go from s to e through a or b; the tunnel between a and b is one-way.
+const referee = `3
+##start
+s 0 0
+a 1 0
+b 1 1
+##end
+e 2 0
+s-a
+s-b
+a-e
+b-e
+a>b`
+
+func TestVerify(t *testing.T) {
+	tests := []struct {
+		name       string
+		transcript string
+		turn, ant  int // zero for a valid transcript
+	}{
+		{"valid", "L1-a L2-b\nL1-e L2-e L3-a\nL3-e", 0, 0},
+		{"one-way tunnel", "L1-a L2-b\nL1-b L2-e\nL1-e L3-a\nL3-e", 0, 0},
+		{"room occupied", "L1-a\nL2-a", 2, 2},
+		{"tunnel used twice", "L1-a L2-a", 1, 2},
+		{"no tunnel", "L1-e", 1, 1},
+		{"against one-way tunnel", "L1-b\nL1-a", 2, 1},
+		{"moves twice", "L1-a L1-e", 1, 1},
+		{"no such ant", "L4-a", 1, 4},
+		{"unknown room", "L1-z", 1, 1},
+		{"already at the end", "L1-a L2-b\nL1-e L2-e L3-a\nL3-e L1-a", 3, 1},
+		{"never reaches the end", "L1-a L2-b\nL1-e L2-e", 2, 3},
+	}
+
+	farm, err := parser.ParseReader(strings.NewReader(referee))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			turns, err := parser.ParseMoves(strings.NewReader(tt.transcript))
+			if err != nil {
+				t.Fatal(err)
+			}
+			n, err := simulation.Verify(farm, turns)
+			if n != len(turns) {
+				t.Errorf("Verify counted %d turns, want %d", n, len(turns))
+			}
+			if tt.turn == 0 {
+				if err != nil {
+					t.Errorf("unexpected violation: %v", err)
+				}
+				return
+			}
+			var v *simulation.Violation
+			if !errors.As(err, &v) {
+				t.Fatalf("got %v, want a *Violation", err)
+			}
+			if v.Turn != tt.turn || v.AntID != tt.ant {
+				t.Errorf("got %v, want turn %d, ant %d", v, tt.turn, tt.ant)
+			}
+		})
+	}
+}
+
+// Two free starts share the end: moves that do not name their start must be
+// taken from whichever start still has a free tunnel that turn.
+func TestVerifyPicksStartWithFreeTunnel(t *testing.T) {
+	const text = `2
+##start
+s0 0 0
+##start
+s1 0 1
+##end
+e0 1 0
+s0-e0
+s1-e0`
+	farm, err := (&parser.Parser{Extended: true}).ParseReader(strings.NewReader(text))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	turns := [][]models.Move{{{AntID: 1, To: "e0", Turn: 1}, {AntID: 2, To: "e0", Turn: 1}}}
+	if n, err := simulation.Verify(farm, turns); err != nil || n != 1 {
+		t.Errorf("Verify = %d, %v; want 1, nil", n, err)
+	}
+
+	turns = [][]models.Move{{{AntID: 1, To: "e0", Turn: 1}, {AntID: 2, To: "e0", Turn: 1}, {AntID: 1, From: "s0", To: "e0", Turn: 1}}}
+	if _, err := simulation.Verify(farm, turns); err == nil {
+		t.Error("Verify accepted an ant moving twice in one turn")
+	}
+}
@@ -0,0 +1,75 @@
+package solver
+
+import (
+	"lem-in/internal/flow"
+	"lem-in/internal/graph"
+)
+
+// Bound is a proven lower bound on the number of turns. Every ant needs at least
+// Shortest turns, and at most Cut ants can cross the narrowest cut between the
+// starts and the ends per turn, so no schedule beats Shortest + ⌈Ants/Cut⌉ - 1.
+// Cut is counted only up to Ants: a wider cut does not change the bound.
+// Bound — доказанная нижняя граница числа ходов. Каждому муравью нужно не меньше
+// Shortest ходов, и за ход через самый узкий разрез между стартами и финишами
+// проходит не больше Cut муравьев, поэтому никакое расписание не лучше
+// Shortest + ⌈Ants/Cut⌉ - 1. Cut считается только до Ants: более широкий разрез
+// не меняет границу.
+type Bound struct {
+	Shortest int
+	Cut      int
+	Ants     int
+	Turns    int
+}
+
+// LowerBound computes the bound for every group of ants that shares its starts
+// and returns the tightest one. It returns ErrNoPath when some ants cannot reach an end.
+// LowerBound вычисляет границу для каждой группы муравьев с общими стартами
+// и возвращает самую сильную. Возвращает ErrNoPath, если часть муравьев не может дойти до финиша.
+func LowerBound(g *graph.Graph, antCount int) (Bound, error) {
+	var best Bound
+	for _, group := range g.AntGroups(antCount) {
+		if group.Count == 0 {
+			continue
+		}
+		b, ok := groupBound(g, group)
+		if !ok {
+			return Bound{}, ErrNoPath
+		}
+		if b.Turns > best.Turns {
+			best = b
+		}
+	}
+	return best, nil
+}
+
+// groupBound ищет кратчайший путь и ширину разреза для муравьев одной группы
+// в той же сети, что и MaxFlow: первый увеличивающий путь самый дешевый,
+// а суммарный поток равен минимальному разрезу
+func groupBound(g *graph.Graph, group graph.AntGroup) (Bound, bool) {
+	net := buildNetwork(g, nil)
+	index := make(map[string]int, len(net.names))
+	for i, name := range net.names {
+		index[name] = i
+	}
+	source := net.flow.AddNode()
+	for _, s := range group.Starts {
+		net.flow.AddEdge(source, 2*index[s]+1, flow.Inf, 0)
+	}
+
+	b := Bound{Ants: group.Count}
+	for b.Cut < group.Count {
+		pushed, cost, ok := net.flow.Augment(source, net.sink, group.Count-b.Cut)
+		if !ok {
+			break
+		}
+		if b.Cut == 0 {
+			b.Shortest = cost
+		}
+		b.Cut += pushed
+	}
+	if b.Cut == 0 {
+		return Bound{}, false
+	}
+	b.Turns = b.Shortest + (b.Ants+b.Cut-1)/b.Cut - 1
+	return b, true
+}
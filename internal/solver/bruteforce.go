@@ -0,0 +1,198 @@
+package solver
+
+import (
+	"context"
+	"lem-in/internal/graph"
+	"lem-in/internal/models"
+	"sort"
+)
+
+func init() {
+	Register("bruteforce", BruteForce{})
+}
+
+// BruteForce enumerates every simple path and tries every combination of
+// paths that fits the room capacities. It is exponential and only suits small maps.
+// BruteForce перебирает все простые пути и все комбинации путей,
+// укладывающиеся во вместимость комнат. Экспоненциален и подходит только для малых карт.
+type BruteForce struct{}
+
+// Solve implements Solver.
+// Solve реализует Solver.
+func (BruteForce) Solve(ctx context.Context, g *graph.Graph, antCount int) (Solution, error) {
+	// 1. Находим ВООБЩЕ все возможные пути от стартов до финишей
+	allPaths, complete := findAllPathsDFS(ctx, g)
+	if len(allPaths) == 0 {
+		if !complete {
+			return Solution{}, ctx.Err()
+		}
+		return Solution{}, ErrNoPath
+	}
+
+	// 2. Генерируем комбинации непересекающихся путей и выбираем лучшую
+	bestCombination, exhausted := findBestPathCombo(ctx, g, allPaths, antCount)
+
+	// 3. Распределяем муравьев
+	sol, ok := newSolution(g, bestCombination, antCount)
+	if !ok {
+		return Solution{}, ErrNoPath
+	}
+	sol.Partial = !complete || !exhausted
+	return sol, nil
+}
+
+// findAllPathsDFS находит все пути без циклов из каждого старта до финишей,
+// не проходящие через другие старты и финиши; при отмене ctx возвращает
+// найденные к этому моменту пути и false
+func findAllPathsDFS(ctx context.Context, g *graph.Graph) ([][]string, bool) {
+	var paths [][]string
+	stopped := false
+	var dfs func(curr string, visited map[string]bool, path []string)
+
+	dfs = func(curr string, visited map[string]bool, path []string) {
+		if stopped || ctx.Err() != nil {
+			stopped = true
+			return
+		}
+		if g.IsEnd(curr) {
+			temp := make([]string, len(path))
+			copy(temp, path)
+			paths = append(paths, temp)
+			return
+		}
+		for _, next := range g.AdjacencyList[curr] {
+			if !visited[next] {
+				visited[next] = true
+				dfs(next, visited, append(path, next))
+				visited[next] = false
+			}
+		}
+	}
+
+	visited := make(map[string]bool)
+	for _, s := range g.Starts {
+		visited[s] = true
+	}
+	for _, s := range g.Starts {
+		dfs(s, visited, []string{s})
+	}
+	return paths, !stopped
+}
+
+// findBestPathCombo перебирает комбинации путей, которые не переполняют комнаты;
+// при отмене ctx возвращает лучшую комбинацию из уже проверенных и false
+func findBestPathCombo(ctx context.Context, g *graph.Graph, allPaths [][]string, antCount int) ([]models.Path, bool) {
+	var bestCombo []models.Path
+	minSteps := int(^uint(0) >> 1)
+	stopped := false
+
+	// Превращаем в структуру Path; при отмене ctx оставляем уже
+	// преобразованные пути, но хотя бы один
+	paths := make([]models.Path, 0, len(allPaths))
+	truncated := false
+	for _, p := range allPaths {
+		if len(paths) > 0 && ctx.Err() != nil {
+			truncated = true
+			break
+		}
+		paths = append(paths, newPath(g, p))
+	}
+
+	// Рекурсивно ищем наборы непересекающихся путей
+	var backtrack func(index int, currentCombo []models.Path)
+	backtrack = func(index int, currentCombo []models.Path) {
+		if len(currentCombo) > 0 {
+			steps := comboSteps(g, currentCombo, antCount)
+			if steps < minSteps {
+				minSteps = steps
+				bestCombo = make([]models.Path, len(currentCombo))
+				copy(bestCombo, currentCombo)
+			}
+		}
+
+		for i := index; i < len(paths); i++ {
+			// Останавливаемся, только когда уже есть хотя бы одна комбинация
+			if stopped || (bestCombo != nil && ctx.Err() != nil) {
+				stopped = true
+				return
+			}
+			if isCompatible(g, currentCombo, paths[i]) {
+				backtrack(i+1, append(currentCombo, paths[i]))
+			}
+		}
+	}
+
+	backtrack(0, []models.Path{})
+
+	// Сортируем пути в комбинации по длине (важно для распределения)
+	sort.Slice(bestCombo, func(i, j int) bool {
+		return bestCombo[i].Len < bestCombo[j].Len
+	})
+
+	return bestCombo, !stopped && !truncated
+}
+
+// isCompatible проверяет, что новый путь не переполняет ни комнаты,
+// ни туннели набора
+func isCompatible(g *graph.Graph, combo []models.Path, newPath models.Path) bool {
+	for _, r2 := range newPath.Rooms[1 : len(newPath.Rooms)-1] {
+		shared := 0
+		for _, p := range combo {
+			for _, r1 := range p.Rooms[1 : len(p.Rooms)-1] {
+				if r1 == r2 {
+					shared++
+					break
+				}
+			}
+		}
+		if shared >= g.Capacities[r2] {
+			return false
+		}
+	}
+	for j := 1; j < len(newPath.Rooms); j++ {
+		t := g.Tunnel(newPath.Rooms[j-1], newPath.Rooms[j])
+		if tunnelUsage(g, combo, t.Key) >= t.Width {
+			return false
+		}
+	}
+	return true
+}
+
+// tunnelUsage считает, сколько путей набора проходят по туннелю
+func tunnelUsage(g *graph.Graph, combo []models.Path, key string) int {
+	count := 0
+	for _, p := range combo {
+		for i := 1; i < len(p.Rooms); i++ {
+			if g.Tunnel(p.Rooms[i-1], p.Rooms[i]).Key == key {
+				count++
+				break
+			}
+		}
+	}
+	return count
+}
+
+// comboSteps оценивает набор путей: для одного старта — формулой, иначе точно,
+// так как муравьи разных стартов не могут делить пути
+func comboSteps(g *graph.Graph, combo []models.Path, antCount int) int {
+	if len(g.Starts) == 1 {
+		return calculateSteps(combo, antCount)
+	}
+	sol, ok := newSolution(g, append([]models.Path{}, combo...), antCount)
+	if !ok {
+		return 1000000
+	}
+	return sol.Turns
+}
+
+// Математический расчет количества строк
+func calculateSteps(paths []models.Path, antCount int) int {
+	if len(paths) == 0 {
+		return 1000000
+	}
+	sumLens := 0
+	for _, p := range paths {
+		sumLens += p.Len
+	}
+	return (antCount + sumLens - 1) / len(paths)
+}
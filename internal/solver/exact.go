@@ -0,0 +1,283 @@
+package solver
+
+import (
+	"context"
+	"lem-in/internal/flow"
+	"lem-in/internal/graph"
+	"lem-in/internal/models"
+	"sort"
+	"strings"
+)
+
+func init() {
+	Register("exact", Exact{})
+}
+
+// Exact finds the true minimum number of turns: it builds a time-expanded flow
+// network for T turns (one copy of every room per turn) and binary-searches the
+// smallest T that lets every ant reach an end. Ants may wait anywhere, so the
+// result is a ground truth for the other strategies. The network grows with
+// rooms × turns, so it only suits small maps. The width of a two-way tunnel
+// longer than one turn is applied to each direction separately.
+// Exact находит истинное минимальное число ходов: строит развернутую во времени
+// сеть потоков для T ходов (своя копия каждой комнаты на каждый ход) и двоичным
+// поиском ищет наименьшее T, при котором все муравьи доходят до финиша. Муравьи
+// могут ждать где угодно, поэтому результат — эталон для других стратегий. Сеть
+// растет как комнаты × ходы и подходит только для малых карт. Ширина двустороннего
+// туннеля длиннее одного хода применяется к каждому направлению отдельно.
+type Exact struct{}
+
+// Solve implements Solver. The solution carries the exact Schedule it was built
+// from, unless the maxflow solution is already optimal and is returned as is.
+// Solve реализует Solver. Решение содержит точное расписание Schedule, по которому
+// оно построено, если только решение maxflow уже не оптимально и не возвращено как есть.
+func (Exact) Solve(ctx context.Context, g *graph.Graph, antCount int) (Solution, error) {
+	// 1. Верхняя граница — решение быстрой стратегии, оно заведомо выполнимо;
+	// без него — достаточное время, которое нужно проверить: группы муравьев
+	// по очереди идут по всем туннелям
+	fast, err := MaxFlow{}.Solve(ctx, g, antCount)
+	hi := fast.Turns
+	var best *timeNetwork
+	if err != nil {
+		if ctx.Err() != nil {
+			return Solution{}, ctx.Err()
+		}
+		hi = antCount
+		for _, t := range g.Tunnels {
+			hi += len(g.AntGroups(antCount)) * t.Length
+		}
+		best = newTimeNetwork(g, hi, antCount)
+		if !best.saturate(ctx, antCount) {
+			if ctx.Err() != nil {
+				return Solution{}, ctx.Err()
+			}
+			return Solution{}, ErrNoPath
+		}
+	}
+
+	// 2. Двоичный поиск наименьшего T, при котором поток вмещает всех муравьев;
+	// при отмене ctx возвращаем быстрое решение с флагом Partial
+	lo := 1
+	for lo < hi {
+		if ctx.Err() != nil {
+			if fast.Paths == nil {
+				return Solution{}, ctx.Err()
+			}
+			fast.Partial = true
+			return fast, nil
+		}
+		mid := (lo + hi) / 2
+		if net := newTimeNetwork(g, mid, antCount); net.saturate(ctx, antCount) {
+			hi, best = mid, net
+		} else if ctx.Err() == nil {
+			lo = mid + 1
+		}
+	}
+
+	// 3. Ни одно T меньше быстрого решения не подошло — оно и есть оптимум
+	if best == nil {
+		return fast, nil
+	}
+
+	// 4. Раскладываем поток на маршруты муравьев
+	return best.solution(g, antCount), nil
+}
+
+// timeNetwork — сеть, где у каждой комнаты своя пара in -> out на каждый ход 0..horizon
+type timeNetwork struct {
+	flow    *flow.Network
+	names   []string
+	horizon int
+	source  int
+	sink    int
+}
+
+// node возвращает вход комнаты i на ходу t; выход — следующая вершина
+func (net *timeNetwork) node(i, t int) int {
+	return 2 * (t*len(net.names) + i)
+}
+
+// newTimeNetwork строит развернутую во времени сеть: ожидание в комнате ведет
+// в ее копию на следующем ходу, туннель длины L — в копию соседней комнаты через L ходов.
+// Переход стоит 2L, ожидание в промежуточной комнате — 1, чтобы муравьи ждали
+// в старте и не шли навстречу друг другу без нужды
+func newTimeNetwork(g *graph.Graph, horizon, antCount int) *timeNetwork {
+	names := make([]string, 0, len(g.Rooms))
+	for name := range g.Rooms {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	index := make(map[string]int, len(names))
+	for i, name := range names {
+		index[name] = i
+	}
+
+	rooms := 2 * len(names) * (horizon + 1)
+	net := &timeNetwork{
+		flow:    flow.New(rooms + 2),
+		names:   names,
+		horizon: horizon,
+		source:  rooms,
+		sink:    rooms + 1,
+	}
+
+	for t := 0; t <= horizon; t++ {
+		for i, name := range names {
+			in := net.node(i, t)
+			terminal := g.IsStart(name) || g.IsEnd(name)
+			capacity, waitCost := g.Capacities[name], 1
+			if terminal {
+				capacity, waitCost = flow.Inf, 0
+			}
+			net.flow.AddEdge(in, in+1, capacity, 0)
+			if g.IsEnd(name) {
+				net.flow.AddEdge(in, net.sink, flow.Inf, 0)
+				continue
+			}
+			if t < horizon {
+				net.flow.AddEdge(in+1, net.node(i, t+1), flow.Inf, waitCost)
+			}
+			for _, next := range g.AdjacencyList[name] {
+				tunnel := g.Tunnel(name, next)
+				if g.IsStart(next) || t+tunnel.Length > horizon {
+					continue
+				}
+				net.flow.AddEdge(in+1, net.node(index[next], t+tunnel.Length), tunnel.Width, 2*tunnel.Length)
+			}
+		}
+	}
+
+	// Каждая группа муравьев получает свою вершину, питающую ее старты
+	for _, group := range g.AntGroups(antCount) {
+		v := net.flow.AddNode()
+		net.flow.AddEdge(net.source, v, group.Count, 0)
+		for _, s := range group.Starts {
+			net.flow.AddEdge(v, net.node(index[s], 0)+1, flow.Inf, 0)
+		}
+	}
+	return net
+}
+
+// saturate проталкивает поток, пока он не вместит всех муравьев, не кончатся пути
+// или не будет отменен ctx
+func (net *timeNetwork) saturate(ctx context.Context, antCount int) bool {
+	total := 0
+	for total < antCount {
+		if ctx.Err() != nil {
+			return false
+		}
+		pushed, _, ok := net.flow.Augment(net.source, net.sink, antCount-total)
+		if !ok {
+			return false
+		}
+		total += pushed
+	}
+	return true
+}
+
+// route — маршрут одного муравья: старт и переходы с ходами прибытия
+type route struct {
+	start string
+	moves []models.Move
+}
+
+// solution раскладывает поток на маршруты, нумерует муравьев внутри групп
+// в порядке выхода и собирает из маршрутов расписание, пути и распределение
+func (net *timeNetwork) solution(g *graph.Graph, antCount int) Solution {
+	routes := net.routes()
+	sort.SliceStable(routes, func(i, j int) bool {
+		return routes[i].moves[0].Turn < routes[j].moves[0].Turn
+	})
+
+	turns := 0
+	byStart := make(map[string][]*route)
+	for i := range routes {
+		r := &routes[i]
+		byStart[r.start] = append(byStart[r.start], r)
+		turns = max(turns, r.moves[len(r.moves)-1].Turn)
+	}
+
+	sol := Solution{Turns: turns, Schedule: make([][]models.Move, turns)}
+	pathIndex := make(map[string]int)
+	for _, group := range g.AntGroups(antCount) {
+		var own []*route
+		for _, s := range group.Starts {
+			own = append(own, byStart[s]...)
+		}
+		sort.SliceStable(own, func(i, j int) bool {
+			return own[i].moves[0].Turn < own[j].moves[0].Turn
+		})
+
+		for k, r := range own {
+			id := group.First + k
+			rooms := []string{r.start}
+			for _, m := range r.moves {
+				m.AntID = id
+				sol.Schedule[m.Turn-1] = append(sol.Schedule[m.Turn-1], m)
+				rooms = append(rooms, m.To)
+			}
+
+			// Муравьи с одинаковой последовательностью комнат делят один путь
+			key := strings.Join(rooms, " ")
+			i, ok := pathIndex[key]
+			if !ok {
+				i = len(sol.Paths)
+				pathIndex[key] = i
+				sol.Paths = append(sol.Paths, newPath(g, rooms))
+				sol.Distribution = append(sol.Distribution, nil)
+			}
+			sol.Distribution[i] = append(sol.Distribution[i], id)
+		}
+	}
+	for _, moves := range sol.Schedule {
+		sort.Slice(moves, func(i, j int) bool {
+			return moves[i].AntID < moves[j].AntID
+		})
+	}
+	return sol
+}
+
+// routes идет по потоку от истока к стоку по одной единице за раз
+func (net *timeNetwork) routes() []route {
+	remaining := make([]int, len(net.flow.Edges))
+	for id, e := range net.flow.Edges {
+		if id%2 == 0 && e.Flow > 0 {
+			remaining[id] = e.Flow
+		}
+	}
+	perLayer := 2 * len(net.names)
+
+	var routes []route
+	for {
+		var r route
+		v := net.source
+		for v != net.sink {
+			next := -1
+			for _, id := range net.flow.Adj[v] {
+				if remaining[id] > 0 {
+					next = id
+					break
+				}
+			}
+			if next == -1 {
+				return routes
+			}
+			remaining[next]--
+			from, to := v, net.flow.Edges[next].To
+			v = to
+			if to >= net.source {
+				continue
+			}
+			room, turn := net.names[to%perLayer/2], to/perLayer
+			if from >= net.source {
+				// Первая комната маршрута — старт группы
+				r.start = room
+				continue
+			}
+			if prev := net.names[from%perLayer/2]; prev != room {
+				r.moves = append(r.moves, models.Move{From: prev, To: room, Turn: turn})
+			}
+		}
+		routes = append(routes, r)
+	}
+}
@@ -0,0 +1,173 @@
+package solver
+
+import (
+	"context"
+	"lem-in/internal/graph"
+	"lem-in/internal/models"
+	"slices"
+)
+
+func init() {
+	Register("greedy", Greedy{})
+}
+
+// Greedy repeatedly takes the shortest path through rooms that still have
+// free capacity and tunnels that are not full yet.
+// It is fast but can block better routes with an early choice.
+// Greedy раз за разом берет кратчайший путь через комнаты со свободной
+// вместимостью и еще не заполненные туннели.
+// Он быстрый, но ранний выбор может перекрыть лучшие маршруты.
+type Greedy struct{}
+
+// Solve implements Solver.
+// Solve реализует Solver.
+func (Greedy) Solve(ctx context.Context, g *graph.Graph, antCount int) (Solution, error) {
+	var groups []graph.AntGroup
+	for _, group := range g.AntGroups(antCount) {
+		if group.Count > 0 {
+			groups = append(groups, group)
+		}
+	}
+
+	// Сначала по пути для каждой группы муравьев; группа, которой путь не достался,
+	// ставится первой, и выбор начинается заново
+	var state greedyState
+	for attempt := 0; ; attempt++ {
+		state = newGreedyState()
+		failed := -1
+		for i, group := range groups {
+			if !state.take(g, group.Starts) {
+				failed = i
+				break
+			}
+		}
+		if failed == -1 {
+			break
+		}
+		if failed == 0 || attempt == len(groups) {
+			return Solution{}, ErrNoPath
+		}
+		starved := groups[failed]
+		groups = append([]graph.AntGroup{starved}, slices.Delete(groups, failed, failed+1)...)
+	}
+
+	// Затем из стартов групп, у которых путей меньше, чем муравьев:
+	// больше путей, чем муравьев, не нужно
+	var best Solution
+	for {
+		if sol, ok := newSolution(g, slices.Clone(state.paths), antCount); ok && (best.Paths == nil || sol.Turns < best.Turns) {
+			best = sol
+		}
+		var from []string
+		for _, group := range groups {
+			if state.count(group.Starts) < group.Count {
+				from = append(from, group.Starts...)
+			}
+		}
+		if len(from) == 0 {
+			break
+		}
+		if ctx.Err() != nil {
+			best.Partial = true
+			break
+		}
+		if !state.take(g, from) {
+			break
+		}
+	}
+	return best, nil
+}
+
+// greedyState — занятые комнаты и туннели и уже выбранные пути
+type greedyState struct {
+	used        map[string]int
+	usedTunnels map[string]int
+	paths       []models.Path
+}
+
+func newGreedyState() greedyState {
+	return greedyState{used: make(map[string]int), usedTunnels: make(map[string]int)}
+}
+
+// take добавляет кратчайший свободный путь из любого из стартов from;
+// false означает, что такого пути нет
+func (st *greedyState) take(g *graph.Graph, from []string) bool {
+	rooms := shortestPath(g, from, st.used, st.usedTunnels)
+	if rooms == nil {
+		return false
+	}
+	for _, r := range rooms[1 : len(rooms)-1] {
+		st.used[r]++
+	}
+	for i := 1; i < len(rooms); i++ {
+		st.usedTunnels[g.Tunnel(rooms[i-1], rooms[i]).Key]++
+	}
+	st.paths = append(st.paths, newPath(g, rooms))
+	return true
+}
+
+// count возвращает число выбранных путей, начинающихся в одном из стартов
+func (st *greedyState) count(starts []string) int {
+	n := 0
+	for _, p := range st.paths {
+		if slices.Contains(starts, p.Rooms[0]) {
+			n++
+		}
+	}
+	return n
+}
+
+// shortestPath ищет кратчайший по числу ходов путь из любого из стартов from
+// до ближайшего финиша в обход заполненных комнат и туннелей. Длины туннелей —
+// небольшие целые числа, поэтому вместо кучи используются корзины по расстоянию;
+// при единичных длинах это обычный BFS
+func shortestPath(g *graph.Graph, from []string, used map[string]int, usedTunnels map[string]int) []string {
+	prev := make(map[string]string)
+	dist := make(map[string]int)
+	for _, s := range from {
+		prev[s], dist[s] = "", 0
+	}
+	done := make(map[string]bool)
+	buckets := [][]string{append([]string{}, from...)}
+	end := ""
+	for d := 0; d < len(buckets) && end == ""; d++ {
+		for i := 0; i < len(buckets[d]); i++ {
+			curr := buckets[d][i]
+			if done[curr] || dist[curr] != d {
+				continue
+			}
+			done[curr] = true
+			if g.IsEnd(curr) {
+				end = curr
+				break
+			}
+			for _, next := range g.AdjacencyList[curr] {
+				t := g.Tunnel(curr, next)
+				if done[next] || g.IsStart(next) || usedTunnels[t.Key] >= t.Width {
+					continue
+				}
+				if !g.IsEnd(next) && used[next] >= g.Capacities[next] {
+					continue
+				}
+				if known, seen := dist[next]; seen && known <= d+t.Length {
+					continue
+				}
+				dist[next] = d + t.Length
+				prev[next] = curr
+				for len(buckets) <= d+t.Length {
+					buckets = append(buckets, nil)
+				}
+				buckets[d+t.Length] = append(buckets[d+t.Length], next)
+			}
+		}
+	}
+
+	if end == "" {
+		return nil
+	}
+	var rooms []string
+	for r := end; r != ""; r = prev[r] {
+		rooms = append([]string{r}, rooms...)
+	}
+	return rooms
+}
@@ -0,0 +1,197 @@
+package solver
+
+import (
+	"context"
+	"lem-in/internal/flow"
+	"lem-in/internal/graph"
+	"lem-in/internal/models"
+	"sort"
+)
+
+func init() {
+	Register("maxflow", MaxFlow{})
+}
+
+// MaxFlow grows a min-cost flow on the node-split graph one path at a time
+// (a room of capacity N can be shared by N paths)
+// and keeps the intermediate path set with the fewest turns.
+// MaxFlow наращивает поток минимальной стоимости в графе с расщепленными комнатами
+// (комнату вместимостью N могут делить N путей) по одному пути и сохраняет промежуточный набор с наименьшим числом ходов.
+type MaxFlow struct{}
+
+// Solve implements Solver.
+// Solve реализует Solver.
+func (MaxFlow) Solve(ctx context.Context, g *graph.Graph, antCount int) (Solution, error) {
+	// 1. Строим сеть с расщеплением комнат
+	net := buildNetwork(g, g.AntGroups(antCount))
+
+	// 2. Наращиваем поток по одному пути и оцениваем каждый промежуточный набор:
+	// для малого числа муравьев лишние пути только удлиняют маршрут.
+	// Первые пути достаются каждой группе муравьев по одному (см. buildNetwork);
+	// если какой-то группе путь не достался, карта не решается непересекающимися путями
+	var best Solution
+	for flowValue := 0; flowValue < antCount; flowValue++ {
+		if ctx.Err() != nil {
+			if best.Paths == nil {
+				return Solution{}, ctx.Err()
+			}
+			best.Partial = true
+			break
+		}
+		if _, _, ok := net.flow.Augment(net.source, net.sink, 1); !ok {
+			break
+		}
+		if flowValue+1 == len(net.groups) && !net.everyGroupServed() {
+			return Solution{}, ErrNoPath
+		}
+
+		if sol, ok := newSolution(g, net.extractPaths(g), antCount); ok && (best.Paths == nil || sol.Turns < best.Turns) {
+			best = sol
+		}
+	}
+	if len(best.Paths) == 0 {
+		return Solution{}, ErrNoPath
+	}
+	return best, nil
+}
+
+// roomNetwork — сеть потоков, где каждая комната расщеплена на вход и выход,
+// а все старты и финиши подключены к общим истоку и стоку
+type roomNetwork struct {
+	flow   *flow.Network
+	names  []string
+	starts []int // выходы всех стартов
+	groups []int // приоритетные дуги из истока в вершины групп муравьев
+	source int
+	sink   int
+}
+
+// buildNetwork расщепляет каждую комнату на пару вершин in -> out с емкостью,
+// равной вместимости комнаты, чтобы через нее проходило не больше путей, чем она вмещает.
+// Исток питает старты через вершину каждой группы муравьев с емкостью, равной
+// числу ее муравьев: лишние пути из стартов одной группы заняли бы комнаты другой.
+// Первая единица потока каждой группы стоит больше любого пути со знаком минус,
+// поэтому поток минимальной стоимости сначала дает путь каждой группе, перестраивая
+// уже проложенные пути, а не оставляет какую-то группу без пути
+func buildNetwork(g *graph.Graph, groups []graph.AntGroup) *roomNetwork {
+	names := make([]string, 0, len(g.Rooms))
+	for name := range g.Rooms {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	index := make(map[string]int, len(names))
+	for i, name := range names {
+		index[name] = i
+	}
+
+	net := &roomNetwork{
+		flow:   flow.New(2*len(names) + 2),
+		names:  names,
+		source: 2 * len(names),
+		sink:   2*len(names) + 1,
+	}
+
+	for i, name := range names {
+		capacity := g.Capacities[name]
+		if g.IsStart(name) || g.IsEnd(name) {
+			capacity = flow.Inf
+		}
+		net.flow.AddEdge(2*i, 2*i+1, capacity, 0)
+	}
+
+	// Туннели ведут из выхода одной комнаты во вход другой: ширина туннеля —
+	// сколько путей могут по нему пройти, длина — его стоимость;
+	// возвращаться в старт или уходить из финиша бессмысленно
+	for _, u := range names {
+		if g.IsEnd(u) {
+			continue
+		}
+		for _, v := range g.AdjacencyList[u] {
+			if g.IsStart(v) {
+				continue
+			}
+			t := g.Tunnel(u, v)
+			net.flow.AddEdge(2*index[u]+1, 2*index[v], t.Width, t.Length)
+		}
+	}
+
+	// Общий исток питает выходы стартов через вершины групп, входы всех финишей
+	// ведут в общий сток
+	for _, s := range g.Starts {
+		net.starts = append(net.starts, 2*index[s]+1)
+	}
+	priority := 1
+	for _, t := range g.Tunnels {
+		priority += t.Length * t.Width
+	}
+	for _, group := range groups {
+		if group.Count <= 0 {
+			continue
+		}
+		node := net.flow.AddNode()
+		net.groups = append(net.groups, net.flow.AddEdge(net.source, node, 1, -priority))
+		if group.Count > 1 {
+			net.flow.AddEdge(net.source, node, group.Count-1, 0)
+		}
+		for _, s := range group.Starts {
+			net.flow.AddEdge(node, 2*index[s]+1, flow.Inf, 0)
+		}
+	}
+	for _, e := range g.Ends {
+		net.flow.AddEdge(2*index[e], net.sink, flow.Inf, 0)
+	}
+	return net
+}
+
+// everyGroupServed сообщает, досталось ли каждой группе муравьев хотя бы по пути
+func (net *roomNetwork) everyGroupServed() bool {
+	for _, id := range net.groups {
+		if net.flow.Edges[id].Flow == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// extractPaths раскладывает текущий поток на пути, начиная с выхода каждого старта,
+// так что вершины истока и групп в пути не попадают
+func (net *roomNetwork) extractPaths(g *graph.Graph) []models.Path {
+	remaining := make([]int, len(net.flow.Edges))
+	for id, e := range net.flow.Edges {
+		if id%2 == 0 && e.Flow > 0 {
+			remaining[id] = e.Flow
+		}
+	}
+
+	var paths []models.Path
+	for _, start := range net.starts {
+		for {
+			rooms := []string{net.names[start/2]}
+			v := start
+			for v != net.sink {
+				next := -1
+				for _, id := range net.flow.Adj[v] {
+					if remaining[id] > 0 {
+						next = id
+						break
+					}
+				}
+				if next == -1 {
+					break
+				}
+				remaining[next]--
+				v = net.flow.Edges[next].To
+				// Дуги внутри комнаты (in -> out) не добавляют новую комнату в путь
+				if v < net.source && v%2 == 0 {
+					rooms = append(rooms, net.names[v/2])
+				}
+			}
+			if v != net.sink {
+				break
+			}
+			paths = append(paths, newPath(g, rooms))
+		}
+	}
+	return paths
+}
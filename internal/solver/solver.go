@@ -1,121 +1,144 @@
+// Package solver chooses the set of paths and distributes ants over them.
+// Пакет solver выбирает набор путей и распределяет по ним муравьев.
 package solver
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"lem-in/internal/graph"
 	"lem-in/internal/models"
+	"slices"
 	"sort"
 )
 
-func Solve(g *graph.Graph, antCount int) ([]models.Path, [][]int, error) {
-	// 1. Находим ВООБЩЕ все возможные пути от Start до End
-	allPaths := findAllPathsDFS(g)
-	if len(allPaths) == 0 {
-		return nil, nil, errors.New("no path found")
-	}
-
-	// 2. Генерируем комбинации непересекающихся путей и выбираем лучшую
-	bestCombination := findBestPathCombo(allPaths, antCount)
-
-	// 3. Распределяем муравьев
-	distribution := distributeAnts(bestCombination, antCount)
+// Default is the name of the strategy used when none is requested.
+// Default — имя стратегии, используемой по умолчанию.
+const Default = "maxflow"
+
+// ErrNoPath is returned when the end room cannot be reached from the start.
+// ErrNoPath возвращается, когда финиш недостижим из старта.
+var ErrNoPath = errors.New("no path found")
+
+// Solution is the chosen set of paths with the ants assigned to each of them.
+// Partial reports that the search was cut short by the context and the
+// solution is only the best one found so far, not necessarily optimal.
+// Schedule is set by strategies that plan every move themselves: it holds the
+// moves of each turn and replaces the simulation of Paths and Distribution.
+// Solution — выбранный набор путей с назначенными на каждый путь муравьями.
+// Partial сообщает, что поиск был прерван контекстом и решение — лишь
+// лучшее из найденных, не обязательно оптимальное.
+// Schedule задают стратегии, которые сами планируют каждый ход: он содержит
+// ходы каждого хода и заменяет симуляцию по Paths и Distribution.
+type Solution struct {
+	Paths        []models.Path
+	Distribution [][]int
+	Turns        int
+	Partial      bool
+	Schedule     [][]models.Move
+}
 
-	return bestCombination, distribution, nil
+// Solver is a path selection strategy. When ctx is done before the search
+// finishes, implementations return the best solution found so far with
+// Partial set, or ctx.Err() if nothing was found yet.
+// Solver — стратегия выбора путей. Если ctx завершается раньше поиска,
+// реализации возвращают лучшее найденное решение с флагом Partial
+// или ctx.Err(), если ничего еще не найдено.
+type Solver interface {
+	Solve(ctx context.Context, g *graph.Graph, antCount int) (Solution, error)
 }
 
-// findAllPathsDFS находит все пути без циклов
-func findAllPathsDFS(g *graph.Graph) [][]string {
-	var paths [][]string
-	var dfs func(curr string, visited map[string]bool, path []string)
-
-	dfs = func(curr string, visited map[string]bool, path []string) {
-		if curr == g.End {
-			temp := make([]string, len(path))
-			copy(temp, path)
-			paths = append(paths, temp)
-			return
-		}
-		for _, next := range g.AdjacencyList[curr] {
-			if !visited[next] {
-				visited[next] = true
-				dfs(next, visited, append(path, next))
-				visited[next] = false
-			}
-		}
-	}
+var registry = make(map[string]Solver)
 
-	visited := map[string]bool{g.Start: true}
-	dfs(g.Start, visited, []string{g.Start})
-	return paths
+// Register makes a strategy available by name. It panics on duplicate names.
+// Register делает стратегию доступной по имени. Паникует при повторном имени.
+func Register(name string, s Solver) {
+	if _, exists := registry[name]; exists {
+		panic(fmt.Sprintf("solver: %q registered twice", name))
+	}
+	registry[name] = s
 }
 
-// findBestPathCombo перебирает комбинации путей, которые не пересекаются по комнатам
-func findBestPathCombo(allPaths [][]string, antCount int) []models.Path {
-	var bestCombo []models.Path
-	minSteps := int(^uint(0) >> 1)
+// Get looks up a registered strategy by name.
+// Get ищет зарегистрированную стратегию по имени.
+func Get(name string) (Solver, bool) {
+	s, ok := registry[name]
+	return s, ok
+}
 
-	// Превращаем в структуру Path и сортируем для стабильности
-	var paths []models.Path
-	for _, p := range allPaths {
-		paths = append(paths, models.Path{Rooms: p, Len: len(p) - 1})
+// Names lists the registered strategies in alphabetical order.
+// Names перечисляет зарегистрированные стратегии в алфавитном порядке.
+func Names() []string {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
 	}
+	sort.Strings(names)
+	return names
+}
+
+// Solve runs the default strategy within the deadline carried by ctx.
+// Solve запускает стратегию по умолчанию в пределах дедлайна из ctx.
+func Solve(ctx context.Context, g *graph.Graph, antCount int) (Solution, error) {
+	return registry[Default].Solve(ctx, g, antCount)
+}
 
-	// Рекурсивно ищем наборы непересекающихся путей
-	var backtrack func(index int, currentCombo []models.Path)
-	backtrack = func(index int, currentCombo []models.Path) {
-		if len(currentCombo) > 0 {
-			steps := calculateSteps(currentCombo, antCount)
-			if steps < minSteps {
-				minSteps = steps
-				bestCombo = make([]models.Path, len(currentCombo))
-				copy(bestCombo, currentCombo)
+// newSolution сортирует пути по длине (важно для распределения) и распределяет
+// муравьев каждой группы по путям из ее стартов; false означает, что группе
+// муравьев не досталось ни одного пути
+func newSolution(g *graph.Graph, paths []models.Path, antCount int) (Solution, bool) {
+	sort.SliceStable(paths, func(i, j int) bool {
+		return paths[i].Len < paths[j].Len
+	})
+	sol := Solution{Paths: paths, Distribution: make([][]int, len(paths))}
+	for _, group := range g.AntGroups(antCount) {
+		var indexes []int
+		var own []models.Path
+		for i, p := range paths {
+			if slices.Contains(group.Starts, p.Rooms[0]) {
+				indexes = append(indexes, i)
+				own = append(own, p)
 			}
 		}
+		if group.Count == 0 {
+			continue
+		}
+		if len(own) == 0 {
+			return Solution{}, false
+		}
 
-		for i := index; i < len(paths); i++ {
-			if isCompatible(currentCombo, paths[i]) {
-				backtrack(i+1, append(currentCombo, paths[i]))
-			}
+		counts := countAnts(own, group.Count)
+		sol.Turns = max(sol.Turns, countTurns(own, counts))
+		for k, ids := range distributeAnts(counts, group.First) {
+			sol.Distribution[indexes[k]] = ids
 		}
 	}
-
-	backtrack(0, []models.Path{})
-
-	// Сортируем пути в комбинации по длине (важно для распределения)
-	sort.Slice(bestCombo, func(i, j int) bool {
-		return bestCombo[i].Len < bestCombo[j].Len
-	})
-
-	return bestCombo
+	return sol, true
 }
 
-func isCompatible(combo []models.Path, newPath models.Path) bool {
-	for _, p := range combo {
-		for _, r1 := range p.Rooms[1 : len(p.Rooms)-1] {
-			for _, r2 := range newPath.Rooms[1 : len(newPath.Rooms)-1] {
-				if r1 == r2 {
-					return false
-				}
-			}
-		}
+// newPath строит путь, длина которого — суммарная длина его туннелей в ходах
+func newPath(g *graph.Graph, rooms []string) models.Path {
+	length := 0
+	for i := 1; i < len(rooms); i++ {
+		length += g.Tunnel(rooms[i-1], rooms[i]).Length
 	}
-	return true
+	return models.Path{Rooms: rooms, Len: length}
 }
 
-// Математический расчет количества строк
-func calculateSteps(paths []models.Path, antCount int) int {
-	if len(paths) == 0 {
-		return 1000000
-	}
-	sumLens := 0
-	for _, p := range paths {
-		sumLens += p.Len
+// countTurns возвращает точное число ходов для набора путей с заданным числом
+// муравьев на каждом: последний муравей на пути выходит на ходу count и идет еще Len-1 ходов
+func countTurns(paths []models.Path, counts []int) int {
+	turns := 0
+	for i, count := range counts {
+		if count > 0 && paths[i].Len+count-1 > turns {
+			turns = paths[i].Len + count - 1
+		}
 	}
-	return (antCount + sumLens - 1) / len(paths)
+	return turns
 }
 
-// Логика распределения ID (оставляем ту же, она работает верно)
-func distributeAnts(paths []models.Path, antCount int) [][]int {
+// countAnts жадно назначает каждого муравья на путь с наименьшим временем выхода
+func countAnts(paths []models.Path, antCount int) []int {
 	counts := make([]int, len(paths))
 	for ant := 0; ant < antCount; ant++ {
 		bestIdx := 0
@@ -128,12 +151,17 @@ func distributeAnts(paths []models.Path, antCount int) [][]int {
 		}
 		counts[bestIdx]++
 	}
+	return counts
+}
 
-	distribution := make([][]int, len(paths))
-	currentID := 1
+// Логика распределения ID (оставляем ту же, она работает верно):
+// ID выдаются по кругу начиная с first; counts расходуется
+func distributeAnts(counts []int, first int) [][]int {
+	distribution := make([][]int, len(counts))
+	currentID := first
 	for {
 		added := false
-		for i := 0; i < len(paths); i++ {
+		for i := 0; i < len(counts); i++ {
 			if counts[i] > 0 {
 				distribution[i] = append(distribution[i], currentID)
 				counts[i]--
@@ -0,0 +1,113 @@
+package solver_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"lem-in/internal/generator"
+	"lem-in/internal/graph"
+	"lem-in/internal/models"
+	"lem-in/internal/parser"
+	"lem-in/internal/simulation"
+	"lem-in/internal/solver"
+)
+
+// solve runs a strategy and checks its transcript with the referee; it returns
+// the number of turns the transcript takes.
+func solve(t *testing.T, name string, farm *models.Farm) int {
+	t.Helper()
+	s, _ := solver.Get(name)
+	g := graph.Build(farm)
+	sol, err := s.Solve(context.Background(), g, farm.Ants)
+	if err != nil {
+		t.Fatalf("%s: %v", name, err)
+	}
+	turns := sol.Schedule
+	if turns == nil {
+		turns = simulation.Run(g, sol.Paths, sol.Distribution)
+	}
+	n, err := simulation.Verify(farm, turns)
+	if err != nil {
+		t.Fatalf("%s: %v", name, err)
+	}
+	return n
+}
+
+type farmCase struct {
+	name string
+	load func() (*models.Farm, error)
+}
+
+// farmCases lists the example maps and small generated maps of every profile
+// that the exact solver handles quickly.
+func farmCases() []farmCase {
+	var cases []farmCase
+	for i := range 8 {
+		file := fmt.Sprintf("example%02d.txt", i)
+		cases = append(cases, farmCase{file, func() (*models.Farm, error) {
+			return parser.Parse("../../" + file)
+		}})
+	}
+	for _, profile := range []string{"corridor", "parallel", "grid", "superposition"} {
+		for seed := range uint64(3) {
+			opts := generator.Options{Profile: profile, Seed: seed, Ants: 7, Size: 3}
+			cases = append(cases, farmCase{fmt.Sprintf("%s seed %d", profile, seed), func() (*models.Farm, error) {
+				lines, err := generator.Generate(opts)
+				if err != nil {
+					return nil, err
+				}
+				return parser.ParseReader(strings.NewReader(strings.Join(lines, "\n")))
+			}})
+		}
+	}
+	return cases
+}
+
+func TestFastSolversAgainstExact(t *testing.T) {
+	for _, tc := range farmCases() {
+		t.Run(tc.name, func(t *testing.T) {
+			farm, err := tc.load()
+			if err != nil {
+				t.Fatal(err)
+			}
+			exact := solve(t, "exact", farm)
+			if got := solve(t, "maxflow", farm); got != exact {
+				t.Errorf("maxflow takes %d turns, exact %d", got, exact)
+			}
+			if got := solve(t, "greedy", farm); got < exact {
+				t.Errorf("greedy takes %d turns, fewer than exact %d", got, exact)
+			}
+		})
+	}
+}
+
+// The shortest path of the fixed start s0 goes through r2, the only way out of
+// the free start s1: the solvers must send s0 the long way round.
+const fixedStart = `4
+##start 1
+s0 0 0
+##start
+s1 0 1
+r1 1 0
+r2 1 1
+##end
+e 2 0
+s0-r2
+s0-r1 2
+s1-r2
+r1-e
+r2-e`
+
+func TestFixedStartLeavesFreeGroupAPath(t *testing.T) {
+	farm, err := (&parser.Parser{Extended: true}).ParseReader(strings.NewReader(fixedStart))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"maxflow", "greedy", "exact"} {
+		if got := solve(t, name, farm); got != 4 {
+			t.Errorf("%s: %d turns, want 4", name, got)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package lemin
+
+import (
+	"lem-in/internal/graph"
+	"lem-in/internal/solver"
+)
+
+// Bound is a proven lower bound on the number of turns: every ant needs at least
+// Shortest turns, and at most Cut ants cross the narrowest cut between the starts
+// and the ends per turn, so no schedule beats Turns = Shortest + ⌈Ants/Cut⌉ - 1.
+// Pruned lists the rooms that lie on no path and were left out.
+// Bound — доказанная нижняя граница числа ходов: каждому муравью нужно не меньше
+// Shortest ходов, и за ход через самый узкий разрез проходит не больше Cut муравьев,
+// поэтому никакое расписание не лучше Turns = Shortest + ⌈Ants/Cut⌉ - 1.
+// Pruned перечисляет комнаты, не лежащие ни на одном пути и исключенные из расчета.
+type Bound struct {
+	Shortest int
+	Cut      int
+	Ants     int
+	Turns    int
+	Pruned   []string
+}
+
+// LowerBound computes the bound for the farm. It returns ErrNoPath when some
+// ants cannot reach an end.
+// LowerBound вычисляет границу для фермы. Возвращает ErrNoPath, если часть
+// муравьев не может дойти до финиша.
+func LowerBound(farm *Farm) (Bound, error) {
+	g := graph.Build(farm.model())
+	report := g.Prune()
+	b, err := solver.LowerBound(g, farm.Ants)
+	if err != nil {
+		return Bound{}, err
+	}
+	return Bound{Shortest: b.Shortest, Cut: b.Cut, Ants: b.Ants, Turns: b.Turns, Pruned: report.Rooms}, nil
+}
+
+// Connectivity explains how a map performs. Throughput is the number of ants that
+// can cross the farm per turn, limited by the rooms and tunnels of the minimum cut.
+// ArticulationPoints and Bridges are the rooms and tunnels whose loss splits the
+// farm; Unreachable rooms cannot be reached from a start, and DeadEnds are reachable
+// rooms that lie on no simple path from a start to an end.
+// Connectivity объясняет, как ведет себя карта. Throughput — число муравьев, которое
+// может пересечь ферму за ход, ограниченное комнатами и туннелями минимального разреза.
+// ArticulationPoints и Bridges — комнаты и туннели, без которых ферма распадается;
+// в комнаты Unreachable нельзя попасть из старта, а DeadEnds — достижимые комнаты,
+// не лежащие ни на одном простом пути от старта к финишу.
+type Connectivity struct {
+	Throughput         int
+	MinCutRooms        []string
+	MinCutTunnels      []string
+	ArticulationPoints []string
+	Bridges            []string
+	Unreachable        []string
+	DeadEnds           []string
+}
+
+// Inspect analyzes the connectivity of the farm.
+// Inspect анализирует связность фермы.
+func Inspect(farm *Farm) Connectivity {
+	g := graph.Build(farm.model())
+	cut := g.MinCut()
+	return Connectivity{
+		Throughput:         cut.Throughput,
+		MinCutRooms:        cut.Rooms,
+		MinCutTunnels:      cut.Tunnels,
+		ArticulationPoints: g.ArticulationPoints(),
+		Bridges:            g.Bridges(),
+		Unreachable:        g.Unreachable(),
+		DeadEnds:           g.DeadEnds(),
+	}
+}
@@ -0,0 +1,212 @@
+package lemin
+
+import (
+	"maps"
+	"slices"
+
+	"lem-in/internal/models"
+	"lem-in/internal/parser"
+	"lem-in/internal/simulation"
+)
+
+// The public types mirror the internal ones field by field, so they are copied
+// at the package boundary and callers never share memory with the internals.
+// Публичные типы повторяют внутренние поле в поле, поэтому копируются на
+// границе пакета, и вызывающий код не делит память с внутренними пакетами.
+
+// newFarm copies a parsed farm into the public type.
+// newFarm копирует разобранную ферму в публичный тип.
+func newFarm(f *models.Farm) *Farm {
+	rooms := make(map[string]*Room, len(f.Rooms))
+	for name, r := range f.Rooms {
+		room := Room(*r)
+		rooms[name] = &room
+	}
+	links := make([]Link, len(f.Links))
+	for i, l := range f.Links {
+		links[i] = Link(l)
+	}
+	return &Farm{
+		Ants:      f.Ants,
+		Rooms:     rooms,
+		Start:     f.Start,
+		End:       f.End,
+		Starts:    slices.Clone(f.Starts),
+		Ends:      slices.Clone(f.Ends),
+		StartAnts: maps.Clone(f.StartAnts),
+		Links:     links,
+		RawLines:  slices.Clone(f.RawLines),
+	}
+}
+
+// model copies the farm back into the internal type.
+// model копирует ферму обратно во внутренний тип.
+func (f *Farm) model() *models.Farm {
+	rooms := make(map[string]*models.Room, len(f.Rooms))
+	for name, r := range f.Rooms {
+		room := models.Room(*r)
+		rooms[name] = &room
+	}
+	links := make([]models.Link, len(f.Links))
+	for i, l := range f.Links {
+		links[i] = models.Link(l)
+	}
+	startAnts := maps.Clone(f.StartAnts)
+	if startAnts == nil {
+		startAnts = make(map[string]int)
+	}
+	return &models.Farm{
+		Ants:      f.Ants,
+		Rooms:     rooms,
+		Start:     f.Start,
+		End:       f.End,
+		Starts:    slices.Clone(f.Starts),
+		Ends:      slices.Clone(f.Ends),
+		StartAnts: startAnts,
+		Links:     links,
+		RawLines:  slices.Clone(f.RawLines),
+	}
+}
+
+// newPaths copies paths into the public type.
+// newPaths копирует пути в публичный тип.
+func newPaths(paths []models.Path) []Path {
+	out := make([]Path, len(paths))
+	for i, p := range paths {
+		out[i] = Path{Rooms: slices.Clone(p.Rooms), Len: p.Len}
+	}
+	return out
+}
+
+// modelPaths copies paths back into the internal type.
+// modelPaths копирует пути обратно во внутренний тип.
+func modelPaths(paths []Path) []models.Path {
+	out := make([]models.Path, len(paths))
+	for i, p := range paths {
+		out[i] = models.Path{Rooms: slices.Clone(p.Rooms), Len: p.Len}
+	}
+	return out
+}
+
+// cloneDistribution copies the ant IDs assigned to every path.
+// cloneDistribution копирует ID муравьев, назначенных каждому пути.
+func cloneDistribution(dist [][]int) [][]int {
+	out := make([][]int, len(dist))
+	for i, ids := range dist {
+		out[i] = slices.Clone(ids)
+	}
+	return out
+}
+
+// newMoves copies the moves of one turn into the public type.
+// newMoves копирует ходы одного хода в публичный тип.
+func newMoves(moves []models.Move) []Move {
+	out := make([]Move, len(moves))
+	for i, m := range moves {
+		out[i] = Move(m)
+	}
+	return out
+}
+
+// newTurns copies the moves of every turn into the public type.
+// newTurns копирует ходы каждого хода в публичный тип.
+func newTurns(turns [][]models.Move) [][]Move {
+	out := make([][]Move, len(turns))
+	for i, moves := range turns {
+		out[i] = newMoves(moves)
+	}
+	return out
+}
+
+// modelMoves copies the moves of one turn back into the internal type.
+// modelMoves копирует ходы одного хода обратно во внутренний тип.
+func modelMoves(moves []Move) []models.Move {
+	out := make([]models.Move, len(moves))
+	for i, m := range moves {
+		out[i] = models.Move(m)
+	}
+	return out
+}
+
+// modelTurns copies the moves of every turn back into the internal type.
+// modelTurns копирует ходы каждого хода обратно во внутренний тип.
+func modelTurns(turns [][]Move) [][]models.Move {
+	out := make([][]models.Move, len(turns))
+	for i, moves := range turns {
+		out[i] = modelMoves(moves)
+	}
+	return out
+}
+
+// modelKinds maps the public error kinds to the internal ones, so the public
+// values stay fixed whatever order the parser declares its kinds in.
+// modelKinds сопоставляет публичные виды ошибок внутренним, чтобы публичные
+// значения не зависели от порядка, в котором их объявляет парсер.
+var modelKinds = map[ErrorKind]parser.ErrorKind{
+	KindFile:                 parser.KindFile,
+	KindAntCount:             parser.KindAntCount,
+	KindRoomFormat:           parser.KindRoomFormat,
+	KindRoomName:             parser.KindRoomName,
+	KindCoordinates:          parser.KindCoordinates,
+	KindDuplicateRoom:        parser.KindDuplicateRoom,
+	KindDuplicateCoordinates: parser.KindDuplicateCoordinates,
+	KindMultipleStart:        parser.KindMultipleStart,
+	KindMultipleEnd:          parser.KindMultipleEnd,
+	KindLinkFormat:           parser.KindLinkFormat,
+	KindMissingStart:         parser.KindMissingStart,
+	KindMissingEnd:           parser.KindMissingEnd,
+	KindNoLinks:              parser.KindNoLinks,
+	KindUnknownRoom:          parser.KindUnknownRoom,
+	KindSelfLink:             parser.KindSelfLink,
+	KindDuplicateLink:        parser.KindDuplicateLink,
+	KindRoomAfterLinks:       parser.KindRoomAfterLinks,
+	KindMoveFormat:           parser.KindMoveFormat,
+	KindCapacity:             parser.KindCapacity,
+	KindTunnel:               parser.KindTunnel,
+	KindStartAnts:            parser.KindStartAnts,
+	KindExtendedOnly:         parser.KindExtendedOnly,
+}
+
+// publicKinds is the inverse of modelKinds.
+// publicKinds — обратное отображение modelKinds.
+var publicKinds = func() map[parser.ErrorKind]ErrorKind {
+	kinds := make(map[parser.ErrorKind]ErrorKind, len(modelKinds))
+	for public, internal := range modelKinds {
+		kinds[internal] = public
+	}
+	return kinds
+}()
+
+// newParseError copies a parse error into the public type.
+// newParseError копирует ошибку разбора в публичный тип.
+func newParseError(e *parser.ParseError) *ParseError {
+	return &ParseError{Line: e.Line, Column: e.Column, Text: e.Text, Kind: publicKinds[e.Kind], Err: e.Err}
+}
+
+// newParseErrors copies a list of parse errors into the public type.
+// newParseErrors копирует список ошибок разбора в публичный тип.
+func newParseErrors(errs parser.ErrorList) []*ParseError {
+	out := make([]*ParseError, len(errs))
+	for i, e := range errs {
+		out[i] = newParseError(e)
+	}
+	return out
+}
+
+// model copies the error back into the internal type, whose methods format it.
+// model копирует ошибку обратно во внутренний тип, методы которого ее форматируют.
+func (e *ParseError) model() *parser.ParseError {
+	return &parser.ParseError{Line: e.Line, Column: e.Column, Text: e.Text, Kind: modelKinds[e.Kind], Err: e.Err}
+}
+
+// newError replaces the internal error types with the public ones; other errors pass unchanged.
+// newError заменяет внутренние типы ошибок публичными; прочие ошибки проходят без изменений.
+func newError(err error) error {
+	switch e := err.(type) {
+	case *parser.ParseError:
+		return newParseError(e)
+	case *simulation.Violation:
+		return &Violation{Turn: e.Turn, AntID: e.AntID, Reason: e.Reason}
+	}
+	return err
+}
@@ -0,0 +1,114 @@
+package lemin
+
+import (
+	"fmt"
+
+	"lem-in/internal/simulation"
+)
+
+// ErrorKind classifies what is wrong with a map or a transcript.
+// ErrorKind классифицирует, что не так с картой или записью ходов.
+type ErrorKind int
+
+// Error kinds reported by Parse, ParseFile, ParseMoves and Lint. Their values
+// belong to the public API and do not follow the order of the parser's kinds.
+// Виды ошибок, о которых сообщают Parse, ParseFile, ParseMoves и Lint. Их значения
+// входят в публичный API и не зависят от порядка видов ошибок парсера.
+const (
+	KindFile ErrorKind = iota
+	KindAntCount
+	KindRoomFormat
+	KindRoomName
+	KindCoordinates
+	KindDuplicateRoom
+	KindDuplicateCoordinates
+	KindMultipleStart
+	KindMultipleEnd
+	KindLinkFormat
+	KindMissingStart
+	KindMissingEnd
+	KindNoLinks
+	KindUnknownRoom
+	KindSelfLink
+	KindDuplicateLink
+	KindRoomAfterLinks
+	KindMoveFormat
+	KindCapacity
+	KindTunnel
+	KindStartAnts
+	KindExtendedOnly
+)
+
+var kindDescriptions = map[ErrorKind]string{
+	KindFile:                 "cannot read input",
+	KindAntCount:             "invalid number of ants",
+	KindRoomFormat:           "invalid room definition",
+	KindRoomName:             "invalid room name",
+	KindCoordinates:          "invalid coordinates",
+	KindDuplicateRoom:        "duplicate room",
+	KindDuplicateCoordinates: "duplicate coordinates",
+	KindMultipleStart:        "multiple start rooms",
+	KindMultipleEnd:          "multiple end rooms",
+	KindLinkFormat:           "invalid link",
+	KindMissingStart:         "no start room",
+	KindMissingEnd:           "no end room",
+	KindNoLinks:              "no links",
+	KindUnknownRoom:          "link to unknown room",
+	KindSelfLink:             "room linked to itself",
+	KindDuplicateLink:        "duplicate link",
+	KindRoomAfterLinks:       "room declared after links",
+	KindMoveFormat:           "invalid move",
+	KindCapacity:             "invalid room capacity",
+	KindTunnel:               "invalid tunnel length or width",
+	KindStartAnts:            "invalid number of ants for start room",
+	KindExtendedOnly:         "allowed only in extended mode",
+}
+
+func (k ErrorKind) String() string {
+	if d, ok := kindDescriptions[k]; ok {
+		return d
+	}
+	return fmt.Sprintf("ErrorKind(%d)", int(k))
+}
+
+// ParseError describes a problem of a map or a transcript with its position.
+// Line and Column are 1-based; they are zero for problems with the map as a whole.
+// ParseError описывает проблему карты или записи ходов и ее положение.
+// Line и Column начинаются с 1; для проблем всей карты они равны нулю.
+type ParseError struct {
+	Line   int
+	Column int
+	Text   string
+	Kind   ErrorKind
+	Err    error
+}
+
+// Error keeps the "ERROR: invalid data format" prefix required by the output format.
+// Error сохраняет префикс "ERROR: invalid data format", требуемый форматом вывода.
+func (e *ParseError) Error() string {
+	return e.model().Error()
+}
+
+// Message describes the problem and its position without the error prefix.
+// Message описывает проблему и ее положение без префикса ошибки.
+func (e *ParseError) Message() string {
+	return e.model().Message()
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// Violation is the first movement rule broken by a transcript; AntID is zero
+// when the rule concerns the turn as a whole.
+// Violation — первое правило передвижения, нарушенное записью ходов; AntID
+// равен нулю, если правило касается хода целиком.
+type Violation struct {
+	Turn   int
+	AntID  int
+	Reason string
+}
+
+func (v *Violation) Error() string {
+	return (*simulation.Violation)(v).Error()
+}
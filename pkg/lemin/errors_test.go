@@ -0,0 +1,28 @@
+package lemin
+
+import (
+	"testing"
+
+	"lem-in/internal/parser"
+)
+
+// Every parser kind must have a public counterpart with the same description,
+// or its errors would surface as KindFile.
+func TestEveryParserKindIsMapped(t *testing.T) {
+	for k := parser.KindFile; k <= parser.KindExtendedOnly; k++ {
+		public, ok := publicKinds[k]
+		if !ok {
+			t.Errorf("parser kind %v has no public kind", k)
+			continue
+		}
+		if modelKinds[public] != k {
+			t.Errorf("public kind %v maps back to %v, want %v", public, modelKinds[public], k)
+		}
+		if public.String() != k.String() {
+			t.Errorf("public kind %q describes parser kind %q", public, k)
+		}
+	}
+	if len(modelKinds) != len(kindDescriptions) {
+		t.Errorf("%d public kinds are mapped, %d are described", len(modelKinds), len(kindDescriptions))
+	}
+}
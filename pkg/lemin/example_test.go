@@ -0,0 +1,102 @@
+package lemin_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"lem-in/pkg/lemin"
+)
+
+const farmText = `3
+##start
+s 0 0
+a 1 0
+b 1 1
+##end
+e 2 0
+s-a
+s-b
+a-e
+b-e`
+
+func Example() {
+	farm, err := lemin.Parse(strings.NewReader(farmText), lemin.ParseOptions{})
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	sol, err := lemin.Solve(context.Background(), farm, lemin.SolveOptions{})
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	turns := lemin.Simulate(farm, sol)
+	for _, moves := range turns {
+		for i, m := range moves {
+			if i > 0 {
+				fmt.Print(" ")
+			}
+			fmt.Printf("L%d-%s", m.AntID, m.To)
+		}
+		fmt.Println()
+	}
+	n, err := lemin.Verify(farm, turns)
+	fmt.Println(n, "turns, verified:", err == nil)
+	// Output:
+	// L1-a L2-b
+	// L1-e L2-e L3-a
+	// L3-e
+	// 3 turns, verified: true
+}
+
+func ExampleParse_error() {
+	_, err := lemin.Parse(strings.NewReader("1\n##start\ns 0 0\ns 1 1\n"), lemin.ParseOptions{})
+	var perr *lemin.ParseError
+	if errors.As(err, &perr) && perr.Kind == lemin.KindDuplicateRoom {
+		fmt.Printf("line %d, column %d: %s\n", perr.Line, perr.Column, perr.Kind)
+	}
+	// Output:
+	// line 4, column 1: duplicate room
+}
+
+func ExampleLint() {
+	for _, e := range lemin.Lint(strings.NewReader("1\n##start\ns 0 0\ns 1 1\ns-x\n"), lemin.ParseOptions{}) {
+		fmt.Println(e.Message())
+	}
+	// Output:
+	// duplicate room (line 4, column 1: "s")
+	// link to unknown room (line 5, column 3: "x")
+	// no end room
+	// no links
+}
+
+func ExampleVerify() {
+	farm, _ := lemin.Parse(strings.NewReader(farmText), lemin.ParseOptions{})
+	moves, _ := lemin.ParseMoves(strings.NewReader("L1-a L2-a\n"))
+	_, err := lemin.Verify(farm, moves)
+	var v *lemin.Violation
+	if errors.As(err, &v) {
+		fmt.Println(v)
+	}
+	// Output:
+	// turn 1, ant 2: tunnel a-s is already used this turn
+}
+
+func ExampleWriteText() {
+	farm, _ := lemin.Parse(strings.NewReader("1\n##start\ns 0 0\n##end\ne 1 0\ns-e"), lemin.ParseOptions{})
+	sol, _ := lemin.Solve(context.Background(), farm, lemin.SolveOptions{Solver: "exact"})
+	lemin.WriteText(os.Stdout, farm, lemin.Simulate(farm, sol))
+	// Output:
+	// 1
+	// ##start
+	// s 0 0
+	// ##end
+	// e 1 0
+	// s-e
+	//
+	// L1-e
+}
@@ -0,0 +1,51 @@
+package lemin
+
+import (
+	"context"
+
+	"lem-in/internal/generator"
+)
+
+// ErrUnproven is returned by ExpectedTurns when the optimal number of turns
+// could not be proven before the context ended.
+// ErrUnproven возвращает ExpectedTurns, когда оптимальное число ходов не удалось
+// доказать до завершения контекста.
+var ErrUnproven = generator.ErrUnproven
+
+// GenerateOptions selects the shape of a generated map. Zero Ants and Size take
+// the profile defaults; Size means the corridor length, the number of routes, the
+// grid side or the number of rooms.
+// GenerateOptions задает форму генерируемой карты. Нулевые Ants и Size берутся
+// из профиля; Size — это длина коридора, число маршрутов, сторона сетки или число комнат.
+type GenerateOptions struct {
+	Profile string
+	Seed    uint64
+	Ants    int
+	Size    int
+}
+
+// Profiles lists the map shapes of Generate in alphabetical order.
+// Profiles перечисляет формы карт Generate в алфавитном порядке.
+func Profiles() []string {
+	return generator.Profiles()
+}
+
+// Generate returns the lines of a random map file; the same options always
+// produce the same map.
+// Generate возвращает строки файла случайной карты; одни и те же параметры
+// всегда дают одну и ту же карту.
+func Generate(opts GenerateOptions) ([]string, error) {
+	return generator.Generate(generator.Options(opts))
+}
+
+// ExpectedTurns proves the optimal number of turns for the lines of a map. When
+// ctx ends first it returns ErrUnproven; other errors are returned unchanged.
+// ExpectedTurns доказывает оптимальное число ходов для строк карты. Если ctx
+// завершается раньше, возвращает ErrUnproven; прочие ошибки возвращаются как есть.
+func ExpectedTurns(ctx context.Context, lines []string) (int, error) {
+	turns, err := generator.ExpectedTurns(ctx, lines)
+	if err != nil {
+		return 0, newError(err)
+	}
+	return turns, nil
+}
@@ -0,0 +1,289 @@
+// Package lemin is the public API of the ant farm solver: it parses maps,
+// finds the best set of paths, simulates the moves of the ants and verifies
+// move transcripts; it also lints, analyzes, generates and writes maps. Its
+// types are independent of the internal packages, and its signatures follow
+// semantic versioning: within a major version they only gain new fields and functions.
+// Пакет lemin — публичный API решателя муравьиной фермы: он разбирает карты,
+// находит лучший набор путей, симулирует ходы муравьев и проверяет записи ходов;
+// также он проверяет, анализирует, генерирует и записывает карты. Его типы
+// не зависят от внутренних пакетов, а сигнатуры следуют семантическому
+// версионированию: в пределах мажорной версии добавляются только новые поля и функции.
+package lemin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+
+	"lem-in/internal/graph"
+	"lem-in/internal/models"
+	"lem-in/internal/parser"
+	"lem-in/internal/simulation"
+	"lem-in/internal/solver"
+)
+
+// Farm is a parsed map: the ants, the rooms with start and end, and the links.
+// Start and End are the first start and end room; Starts and Ends list every one
+// of them in extended maps, and StartAnts holds the "##start N" counts. RawLines
+// is the input as read, printed before the moves.
+// Farm — разобранная карта: муравьи, комнаты со стартом и финишем, связи.
+// Start и End — первые старт и финиш; Starts и Ends перечисляют все старты и финиши
+// расширенных карт, а StartAnts хранит числа из "##start N". RawLines — вход
+// в прочитанном виде, который выводится перед ходами.
+type Farm struct {
+	Ants      int
+	Rooms     map[string]*Room
+	Start     string
+	End       string
+	Starts    []string
+	Ends      []string
+	StartAnts map[string]int
+	Links     []Link
+	RawLines  []string
+}
+
+// Room is a room of the farm with its coordinates and capacity.
+// Room — комната фермы с координатами и вместимостью.
+type Room struct {
+	Name     string
+	X, Y     int
+	Capacity int
+}
+
+// Link is a tunnel between two rooms with its length, width and direction.
+// Link — туннель между двумя комнатами с длиной, шириной и направлением.
+type Link struct {
+	From, To      string
+	Length, Width int
+	Directed      bool
+}
+
+// Path is a sequence of rooms from a start to an end; Len is its length in turns.
+// Path — последовательность комнат от старта до финиша; Len — ее длина в ходах.
+type Path struct {
+	Rooms []string
+	Len   int
+}
+
+// Move is one ant arriving in room To during a turn; From is empty when unknown.
+// Move — прибытие одного муравья в комнату To за ход; From пусто, если неизвестно.
+type Move struct {
+	AntID    int
+	From, To string
+	Turn     int
+}
+
+// TurnEvent holds the moves of one turn, sorted by ant ID.
+// TurnEvent содержит ходы одного хода, отсортированные по ID муравья.
+type TurnEvent struct {
+	Turn  int
+	Moves []Move
+}
+
+// DefaultSolver is the strategy used when SolveOptions names none.
+// DefaultSolver — стратегия, используемая, если SolveOptions ее не называет.
+const DefaultSolver = solver.Default
+
+var (
+	// ErrNoPath is returned by Solve when no end room can be reached.
+	// ErrNoPath возвращает Solve, когда финиш недостижим.
+	ErrNoPath = solver.ErrNoPath
+
+	// ErrUnknownSolver is returned by Solve for a strategy name not listed by Solvers.
+	// ErrUnknownSolver возвращает Solve для имени стратегии, которого нет в Solvers.
+	ErrUnknownSolver = errors.New("unknown solver")
+)
+
+// ParseOptions selects the dialect of the map format.
+// ParseOptions выбирает диалект формата карты.
+type ParseOptions struct {
+	// Lenient turns bad links into warnings instead of rejecting the map.
+	// Lenient превращает неверные связи в предупреждения вместо отказа.
+	Lenient bool
+
+	// Extended accepts several start and end rooms and per-start ant counts.
+	// Extended допускает несколько стартов и финишей и число муравьев у старта.
+	Extended bool
+
+	// Warn, when set, receives every problem skipped in lenient mode.
+	// Warn, если задан, получает каждую проблему, пропущенную в мягком режиме.
+	Warn func(*ParseError)
+}
+
+// SolveOptions selects the strategy of Solve.
+// SolveOptions выбирает стратегию Solve.
+type SolveOptions struct {
+	// Solver is the name of a strategy from Solvers; empty means DefaultSolver.
+	// Solver — имя стратегии из Solvers; пустое означает DefaultSolver.
+	Solver string
+
+	// NoPrune keeps the rooms that lie on no path in the search instead of removing them first.
+	// NoPrune оставляет в поиске комнаты, не лежащие ни на одном пути, вместо их удаления.
+	NoPrune bool
+}
+
+// Solution is the chosen set of paths with the IDs of the ants sent along each
+// of them. Partial reports that the context ended the search early and the
+// solution may not be optimal. Pruned lists the rooms that lie on no path and
+// were left out of the search.
+// Solution — выбранный набор путей с ID муравьев, отправленных по каждому из них.
+// Partial сообщает, что контекст прервал поиск и решение может быть не оптимальным.
+// Pruned перечисляет комнаты, не лежащие ни на одном пути и исключенные из поиска.
+type Solution struct {
+	Solver       string
+	Paths        []Path
+	Distribution [][]int
+	Turns        int
+	Partial      bool
+	Pruned       []string
+
+	// schedule holds the moves of strategies that plan every turn themselves
+	// schedule хранит ходы стратегий, которые сами планируют каждый ход
+	schedule [][]models.Move
+}
+
+// Parse reads a map from r. It stops at the first problem and returns it as a *ParseError.
+// Parse читает карту из r. Останавливается на первой проблеме и возвращает ее как *ParseError.
+func Parse(r io.Reader, opts ParseOptions) (*Farm, error) {
+	p := &parser.Parser{Lenient: opts.Lenient, Extended: opts.Extended}
+	farm, err := p.ParseReader(r)
+	warn(p, opts)
+	if err != nil {
+		return nil, newError(err)
+	}
+	return newFarm(farm), nil
+}
+
+// ParseFile reads a map from a file; errors name the file.
+// ParseFile читает карту из файла; ошибки называют файл.
+func ParseFile(path string, opts ParseOptions) (*Farm, error) {
+	p := &parser.Parser{Lenient: opts.Lenient, Extended: opts.Extended}
+	farm, err := p.Parse(path)
+	warn(p, opts)
+	if err != nil {
+		return nil, newError(err)
+	}
+	return newFarm(farm), nil
+}
+
+// warn passes the warnings of the parser to the callback of the options.
+// warn передает предупреждения парсера обработчику из параметров.
+func warn(p *parser.Parser, opts ParseOptions) {
+	if opts.Warn == nil {
+		return
+	}
+	for _, w := range p.Warnings {
+		opts.Warn(newParseError(w))
+	}
+}
+
+// ParseMoves reads a move transcript, one turn per line such as "L1-a L2-b".
+// ParseMoves читает запись ходов, по одному ходу в строке, например "L1-a L2-b".
+func ParseMoves(r io.Reader) ([][]Move, error) {
+	turns, err := parser.ParseMoves(r)
+	if err != nil {
+		return nil, newError(err)
+	}
+	return newTurns(turns), nil
+}
+
+// Lint reads a map from r and reports every problem in input order instead of
+// stopping at the first one; an empty result means the map is valid. Lenient is ignored.
+// Lint читает карту из r и сообщает обо всех проблемах в порядке следования,
+// а не только о первой; пустой результат означает, что карта валидна. Lenient не учитывается.
+func Lint(r io.Reader, opts ParseOptions) []*ParseError {
+	return newParseErrors((&parser.Parser{Extended: opts.Extended}).LintReader(r))
+}
+
+// LintFile reports every problem of a map file like Lint.
+// LintFile сообщает обо всех проблемах файла карты, как Lint.
+func LintFile(path string, opts ParseOptions) []*ParseError {
+	return newParseErrors((&parser.Parser{Extended: opts.Extended}).Lint(path))
+}
+
+// Solvers lists the names of the available strategies in alphabetical order.
+// Solvers перечисляет имена доступных стратегий в алфавитном порядке.
+func Solvers() []string {
+	return solver.Names()
+}
+
+// Solve finds the paths of the ants within the deadline carried by ctx. When
+// ctx ends the search early, it returns the best solution found so far with
+// Partial set, or ctx.Err() when nothing was found yet.
+// Solve находит пути муравьев в пределах дедлайна из ctx. Если ctx прерывает
+// поиск, возвращает лучшее найденное решение с флагом Partial или ctx.Err(),
+// если ничего еще не найдено.
+func Solve(ctx context.Context, farm *Farm, opts SolveOptions) (*Solution, error) {
+	name := opts.Solver
+	if name == "" {
+		name = DefaultSolver
+	}
+	s, ok := solver.Get(name)
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownSolver, name)
+	}
+
+	g := graph.Build(farm.model())
+	var report graph.PruneReport
+	if !opts.NoPrune {
+		report = g.Prune()
+	}
+	sol, err := s.Solve(ctx, g, farm.Ants)
+	if err != nil {
+		return nil, err
+	}
+	return &Solution{
+		Solver:       name,
+		Paths:        newPaths(sol.Paths),
+		Distribution: cloneDistribution(sol.Distribution),
+		Turns:        sol.Turns,
+		Partial:      sol.Partial,
+		Pruned:       report.Rooms,
+		schedule:     sol.Schedule,
+	}, nil
+}
+
+// Simulate moves the ants of the solution turn by turn and returns the moves
+// of every turn, sorted by ant ID within a turn.
+// Simulate передвигает муравьев решения ход за ходом и возвращает ходы
+// каждого хода, отсортированные по ID муравья внутри хода.
+func Simulate(farm *Farm, sol *Solution) [][]Move {
+	if sol.schedule != nil {
+		return newTurns(sol.schedule)
+	}
+	return newTurns(simulation.Run(graph.Build(farm.model()), modelPaths(sol.Paths), cloneDistribution(sol.Distribution)))
+}
+
+// Turns yields the turns of Simulate one by one as soon as they are computed,
+// so huge simulations can be streamed without buffering them.
+// Turns выдает ходы Simulate по одному сразу после их вычисления, чтобы
+// большие симуляции можно было передавать потоком без буферизации.
+func Turns(farm *Farm, sol *Solution) iter.Seq[TurnEvent] {
+	events := simulation.Replay(sol.schedule)
+	if sol.schedule == nil {
+		events = simulation.Turns(graph.Build(farm.model()), modelPaths(sol.Paths), cloneDistribution(sol.Distribution))
+	}
+	return func(yield func(TurnEvent) bool) {
+		for event := range events {
+			if !yield(TurnEvent{Turn: event.Turn, Moves: newMoves(event.Moves)}) {
+				return
+			}
+		}
+	}
+}
+
+// Verify replays a transcript against the farm and checks every movement rule.
+// Moves with an empty From are taken from the ant's current room. It returns the
+// number of turns of the transcript and a *Violation for the first broken rule.
+// Verify воспроизводит запись ходов на ферме и проверяет все правила передвижения.
+// Ходы с пустым From делаются из текущей комнаты муравья. Возвращает число ходов
+// записи и *Violation для первого нарушенного правила.
+func Verify(farm *Farm, turns [][]Move) (int, error) {
+	n, err := simulation.Verify(farm.model(), modelTurns(turns))
+	if err != nil {
+		return n, newError(err)
+	}
+	return n, nil
+}
@@ -0,0 +1,48 @@
+package lemin
+
+import (
+	"io"
+	"iter"
+
+	"lem-in/internal/formatter"
+	"lem-in/internal/simulation"
+)
+
+// WriteText writes the map as it was read, an empty line and one line of
+// "Lx-room" moves per turn: the standard output of the solver.
+// WriteText записывает карту в прочитанном виде, пустую строку и по строке
+// ходов "Lx-room" на каждый ход: стандартный вывод решателя.
+func WriteText(w io.Writer, farm *Farm, turns [][]Move) error {
+	return formatter.Write(w, farm.RawLines, modelTurns(turns))
+}
+
+// WriteJSON writes the farm, the paths of the solution and the moves of every
+// turn as one JSON document.
+// WriteJSON записывает ферму, пути решения и ходы каждого хода одним документом JSON.
+func WriteJSON(w io.Writer, farm *Farm, sol *Solution, turns [][]Move) error {
+	return formatter.WriteJSON(w, farm.model(), modelPaths(sol.Paths), cloneDistribution(sol.Distribution), modelTurns(turns))
+}
+
+// WriteNDJSON writes one JSON line per turn as the events arrive, e.g. from Turns.
+// WriteNDJSON записывает по JSON-строке на ход по мере поступления событий, например из Turns.
+func WriteNDJSON(w io.Writer, events iter.Seq[TurnEvent]) error {
+	return formatter.WriteNDJSON(w, func(yield func(simulation.TurnEvent) bool) {
+		for event := range events {
+			if !yield(simulation.TurnEvent{Turn: event.Turn, Moves: modelMoves(event.Moves)}) {
+				return
+			}
+		}
+	})
+}
+
+// WriteDOT writes the farm as a Graphviz graph with the rooms at their coordinates.
+// WriteDOT записывает ферму графом Graphviz с комнатами в их координатах.
+func WriteDOT(w io.Writer, farm *Farm) error {
+	return formatter.WriteDOT(w, farm.model())
+}
+
+// WriteFarmJSON writes the farm alone as a JSON document.
+// WriteFarmJSON записывает одну ферму документом JSON.
+func WriteFarmJSON(w io.Writer, farm *Farm) error {
+	return formatter.WriteFarmJSON(w, farm.model())
+}